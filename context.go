@@ -0,0 +1,75 @@
+package golog
+
+import "context"
+
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	fieldsContextKey
+)
+
+// NewContext returns a copy of ctx carries the logger.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext returns the logger carried by ctx, if there is none, DefaultLogger was returned.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerContextKey).(Logger); ok {
+		return l
+	}
+	return DefaultLogger
+}
+
+// ContextWithFields returns a copy of ctx carries fields in ctx and the new fields,
+// args is same as the arguments of WithFields.
+func ContextWithFields(ctx context.Context, args ...interface{}) context.Context {
+	prev := ContextFields(ctx)
+	fields := make([]Field, len(prev), len(prev)+len(args)/2)
+	copy(fields, prev)
+	return context.WithValue(ctx, fieldsContextKey, appendFields(fields, args...))
+}
+
+// ContextFields returns fields carried by ctx, it should not be modified.
+func ContextFields(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsContextKey).([]Field)
+	return fields
+}
+
+func DepthContext(ctx context.Context, level Level, depth int, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, level, depth+1, args...)
+}
+
+func TraceContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelTrace, 1, args...)
+}
+
+func DebugContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelDebug, 1, args...)
+}
+
+func InfoContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelInfo, 1, args...)
+}
+
+func WarnContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelWarn, 1, args...)
+}
+
+func ErrorContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelError, 1, args...)
+}
+
+func PanicContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelPanic, 1, args...)
+}
+
+func FatalContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DepthContext(ctx, LevelFatal, 1, args...)
+}
+
+// WithContext returns a Log contains fields carried by ctx of the logger carried by ctx.
+func WithContext(ctx context.Context) *Log {
+	return FromContext(ctx).WithContext(ctx)
+}
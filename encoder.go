@@ -2,10 +2,13 @@ package golog
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math"
 	"strconv"
 	"time"
+	"unicode/utf8"
 )
 
 type Encoder interface {
@@ -17,6 +20,17 @@ type TypeEncoder struct {
 	Default    func(buf *bytes.Buffer, v interface{})
 	String     func(buf *bytes.Buffer, s string)
 	Bytes      func(buf *bytes.Buffer, b []byte)
+	Error      func(buf *bytes.Buffer, err error)
+	// encode keys of object entries with the key separator, the key is quoted if it's nil
+	Key func(buf *bytes.Buffer, key string)
+	// escape '<', '>' and '&' in quoted strings to make it safe to embed in HTML
+	EscapeHTML bool
+	// literal of nil values, "null" if empty
+	Null string
+	// separator between array elements or object entries, "," if empty
+	ElemSeparator string
+	// separator between object key and value, ":" if empty
+	KeySeparator string
 }
 
 func (t *TypeEncoder) EncodeVal(buf *bytes.Buffer, val interface{}) {
@@ -57,101 +71,88 @@ func (t *TypeEncoder) EncodeVal(buf *bytes.Buffer, val interface{}) {
 		t.EncodeTime(buf, v)
 	case time.Duration:
 		t.EncodeDuration(buf, v)
-	//case []int8:
-	//	for _, v := range v {
-	//		t.EncodeInt(buf, int64(v))
-	//	}
-	//case []int16:
-	//	for _, v := range v {
-	//		t.EncodeInt(buf, int64(v))
-	//	}
-	//case []int32:
-	//	for _, v := range v {
-	//		t.EncodeInt(buf, int64(v))
-	//	}
-	//case []int64:
-	//	for _, v := range v {
-	//		t.EncodeInt(buf, int64(v))
-	//	}
-	//case []int:
-	//	for _, v := range v {
-	//		t.EncodeInt(buf, int64(v))
-	//	}
-	//case []uint16:
-	//	for _, v := range v {
-	//		t.EncodeUint(buf, uint64(v))
-	//	}
-	//case []uint32:
-	//	for _, v := range v {
-	//		t.EncodeUint(buf, uint64(v))
-	//	}
-	//case []uint64:
-	//	for _, v := range v {
-	//		t.EncodeUint(buf, uint64(v))
-	//	}
-	//case []uint:
-	//	for _, v := range v {
-	//		t.EncodeUint(buf, uint64(v))
-	//	}
-	//case []float32:
-	//	for _, v := range v {
-	//		t.EncodeFloat(buf, float64(v))
-	//	}
-	//case []float64:
-	//	for _, v := range v {
-	//		t.EncodeFloat(buf, float64(v))
-	//	}
-	//case []complex64:
-	//	for _, v := range v {
-	//		t.EncodeComplex(buf, complex128(v))
-	//	}
-	//case []complex128:
-	//	for _, v := range v {
-	//		t.EncodeComplex(buf, complex128(v))
-	//	}
-	//case []bool:
-	//	for _, v := range v {
-	//		t.EncodeBool(buf, v)
-	//	}
-	//case []string:
-	//	for _, v := range v {
-	//		t.EncodeString(buf, v)
-	//	}
-	//case []time.Time:
-	//	for _, v := range v {
-	//		t.EncodeTime(buf, v)
-	//	}
-	//case []time.Duration:
-	//	for _, v := range v {
-	//		t.EncodeDuration(buf, v)
-	//	}
-	case fmt.Stringer:
-		t.EncodeString(buf, v.String())
-	case error:
-		if v != nil {
-			t.EncodeString(buf, v.Error())
+	case *time.Time:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeTime(buf, *v)
+		}
+	case *time.Duration:
+		if v == nil {
+			t.EncodeNil(buf)
 		} else {
-			t.EncodeString(buf, "")
+			t.EncodeDuration(buf, *v)
 		}
+	case nil:
+		t.EncodeNil(buf)
 	case []byte:
 		t.EncodeBytes(buf, v)
+	case json.RawMessage:
+		t.EncodeRawJSON(buf, v)
 	case *json.RawMessage:
-		t.EncodeBytes(buf, *v)
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeRawJSON(buf, *v)
+		}
+	case ObjectMarshaler:
+		t.EncodeObject(buf, v)
+	case ArrayMarshaler:
+		t.EncodeArray(buf, v)
+	case error:
+		t.EncodeError(buf, v)
+	case fmt.Stringer:
+		t.EncodeString(buf, v.String())
 	default:
-		t.Default(buf, v)
+		if !t.encodeComposite(buf, v) {
+			t.Default(buf, v)
+		}
+	}
+}
+
+// EncodeFieldValue encode the value of field by the kind stored by typed constructors.
+func (t *TypeEncoder) EncodeFieldValue(buf *bytes.Buffer, f *Field) {
+	switch f.kind {
+	case fieldString:
+		t.EncodeString(buf, f.str)
+	case fieldInt:
+		t.EncodeInt(buf, f.num)
+	case fieldUint:
+		t.EncodeUint(buf, uint64(f.num))
+	case fieldFloat:
+		t.EncodeFloat(buf, math.Float64frombits(uint64(f.num)))
+	case fieldBool:
+		t.EncodeBool(buf, f.num != 0)
+	case fieldDuration:
+		t.EncodeDuration(buf, time.Duration(f.num))
+	case fieldTime:
+		t.EncodeTime(buf, f.time())
+	default:
+		t.EncodeVal(buf, f.Value)
+	}
+}
+
+func (t *TypeEncoder) EncodeNil(buf *bytes.Buffer) {
+	if t.Null != "" {
+		buf.WriteString(t.Null)
+	} else {
+		buf.WriteString("null")
 	}
 }
 
 func (*TypeEncoder) EncodeInt(buf *bytes.Buffer, n int64) {
-	buf.WriteString(strconv.FormatInt(n, 10))
+	var b [24]byte
+	buf.Write(strconv.AppendInt(b[:0], n, 10))
 }
 
 func (*TypeEncoder) EncodeUint(buf *bytes.Buffer, n uint64) {
-	buf.WriteString(strconv.FormatUint(n, 10))
+	var b [24]byte
+	buf.Write(strconv.AppendUint(b[:0], n, 10))
 }
 
 func (*TypeEncoder) EncodeFloat(buf *bytes.Buffer, n float64) {
-	buf.WriteString(strconv.FormatFloat(n, 'f', 4, 64))
+	var b [32]byte
+	buf.Write(strconv.AppendFloat(b[:0], n, 'f', 4, 64))
 }
 
 func (*TypeEncoder) EncodeComplex(buf *bytes.Buffer, s complex128) {
@@ -159,7 +160,12 @@ func (*TypeEncoder) EncodeComplex(buf *bytes.Buffer, s complex128) {
 }
 
 func (t *TypeEncoder) EncodeTime(buf *bytes.Buffer, time time.Time) {
-	t.EncodeString(buf, time.Format(t.Timeformat))
+	if t.String != nil {
+		t.String(buf, time.Format(t.Timeformat))
+		return
+	}
+	var b [64]byte
+	t.EncodeBytesWithQuote(buf, time.AppendFormat(b[:0], t.Timeformat))
 }
 
 func (t *TypeEncoder) EncodeDuration(buf *bytes.Buffer, dur time.Duration) {
@@ -183,17 +189,64 @@ func (t *TypeEncoder) EncodeString(buf *bytes.Buffer, s string) {
 	t.EncodeStringWithQuote(buf, s)
 }
 
+// EncodeStringWithQuote encode string as a quoted JSON string, invalid UTF-8 bytes are
+// replaced by U+FFFD.
 func (t *TypeEncoder) EncodeStringWithQuote(buf *bytes.Buffer, s string) {
 	buf.WriteByte('"')
-	for _, b := range s {
-		if b == '"' {
-			buf.WriteByte('\\')
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if t.needEscape(b) {
+				buf.WriteString(s[start:i])
+				writeEscapedByte(buf, b)
+				start = i + 1
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' {
+			buf.WriteString(s[start:i])
+			writeEscapedRune(buf, r)
+			start = i + size
 		}
-		buf.WriteRune(b)
+		i += size
 	}
+	buf.WriteString(s[start:])
 	buf.WriteByte('"')
 }
 
+func (t *TypeEncoder) needEscape(b byte) bool {
+	return b < 0x20 || b == '"' || b == '\\' || t.EscapeHTML && (b == '<' || b == '>' || b == '&')
+}
+
+const hexDigits = "0123456789abcdef"
+
+func writeEscapedByte(buf *bytes.Buffer, b byte) {
+	switch b {
+	case '"', '\\':
+		buf.WriteByte('\\')
+		buf.WriteByte(b)
+	case '\n':
+		buf.WriteString(`\n`)
+	case '\r':
+		buf.WriteString(`\r`)
+	case '\t':
+		buf.WriteString(`\t`)
+	default:
+		buf.WriteString(`\u00`)
+		buf.WriteByte(hexDigits[b>>4])
+		buf.WriteByte(hexDigits[b&0xF])
+	}
+}
+
+func writeEscapedRune(buf *bytes.Buffer, r rune) {
+	buf.WriteString(`\u`)
+	for shift := 12; shift >= 0; shift -= 4 {
+		buf.WriteByte(hexDigits[r>>uint(shift)&0xF])
+	}
+}
+
 func (t *TypeEncoder) EncodeBytes(buf *bytes.Buffer, bs []byte) {
 	if t.Bytes != nil {
 		t.Bytes(buf, bs)
@@ -203,13 +256,147 @@ func (t *TypeEncoder) EncodeBytes(buf *bytes.Buffer, bs []byte) {
 	t.EncodeBytesWithQuote(buf, bs)
 }
 
+// EncodeBytesWithQuote is same as EncodeStringWithQuote, but for bytes.
 func (t *TypeEncoder) EncodeBytesWithQuote(buf *bytes.Buffer, bs []byte) {
 	buf.WriteByte('"')
-	for _, b := range bs {
-		if b == '"' {
-			buf.WriteByte('\\')
+	start := 0
+	for i := 0; i < len(bs); {
+		if b := bs[i]; b < utf8.RuneSelf {
+			if t.needEscape(b) {
+				buf.Write(bs[start:i])
+				writeEscapedByte(buf, b)
+				start = i + 1
+			}
+			i++
+			continue
 		}
-		buf.WriteByte(b)
+		r, size := utf8.DecodeRune(bs[i:])
+		if r == utf8.RuneError && size == 1 || r == '\u2028' || r == '\u2029' {
+			buf.Write(bs[start:i])
+			writeEscapedRune(buf, r)
+			start = i + size
+		}
+		i += size
 	}
+	buf.Write(bs[start:])
 	buf.WriteByte('"')
 }
+
+// EncodeBytesBase64 encode bytes as a quoted base64 string.
+func (t *TypeEncoder) EncodeBytesBase64(buf *bytes.Buffer, bs []byte) {
+	buf.WriteByte('"')
+	enc := base64.NewEncoder(base64.StdEncoding, buf)
+	enc.Write(bs)
+	enc.Close()
+	buf.WriteByte('"')
+}
+
+// EncodeRawJSON embed compacted raw JSON, if it's not well-formed, it's encoded as a quoted string,
+// empty raw JSON is encoded as nil value.
+func (t *TypeEncoder) EncodeRawJSON(buf *bytes.Buffer, raw []byte) {
+	if len(raw) == 0 {
+		t.EncodeNil(buf)
+		return
+	}
+	n := buf.Len()
+	if err := json.Compact(buf, raw); err != nil {
+		buf.Truncate(n)
+		t.EncodeBytesWithQuote(buf, raw)
+	}
+}
+
+func (t *TypeEncoder) EncodeError(buf *bytes.Buffer, err error) {
+	if t.Error != nil {
+		t.Error(buf, err)
+		return
+	}
+
+	t.EncodeString(buf, err.Error())
+}
+
+// maxErrorDepth limits the depth of error chains to be encoded.
+const maxErrorDepth = 16
+
+// unwrapErrors returns errors wrapped by err, include errors of errors.Join.
+func unwrapErrors(err error) []error {
+	switch e := err.(type) {
+	case interface{ Unwrap() []error }:
+		return e.Unwrap()
+	case interface{ Unwrap() error }:
+		if cause := e.Unwrap(); cause != nil {
+			return []error{cause}
+		}
+	}
+	return nil
+}
+
+// EncodeErrorObject encode error as a JSON object contains the message, the concrete type and
+// the wrapped errors, in "cause" for single wrapped error, or "causes" for multiple errors.
+func (t *TypeEncoder) EncodeErrorObject(buf *bytes.Buffer, err error) {
+	t.encodeErrorObject(buf, err, 0)
+}
+
+func (t *TypeEncoder) encodeErrorObject(buf *bytes.Buffer, err error, depth int) {
+	buf.WriteString(`{"msg":`)
+	t.EncodeStringWithQuote(buf, err.Error())
+	buf.WriteString(`,"type":`)
+	t.EncodeStringWithQuote(buf, fmt.Sprintf("%T", err))
+
+	causes := unwrapErrors(err)
+	if depth >= maxErrorDepth {
+		causes = nil
+	}
+	switch len(causes) {
+	case 0:
+	case 1:
+		buf.WriteString(`,"cause":`)
+		t.encodeErrorObject(buf, causes[0], depth+1)
+	default:
+		buf.WriteString(`,"causes":[`)
+		for i, cause := range causes {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			t.encodeErrorObject(buf, cause, depth+1)
+		}
+		buf.WriteByte(']')
+	}
+	buf.WriteByte('}')
+}
+
+// EncodeErrorChain encode error as quoted message and type followed by the wrapped errors,
+// such as `"read: EOF" (*fmt.wrapError) cause: "EOF" (*errors.errorString)`.
+func (t *TypeEncoder) EncodeErrorChain(buf *bytes.Buffer, err error) {
+	t.encodeErrorChain(buf, err, 0)
+}
+
+func (t *TypeEncoder) encodeErrorChain(buf *bytes.Buffer, err error, depth int) {
+	for ; err != nil; depth++ {
+		t.EncodeStringWithQuote(buf, err.Error())
+		buf.WriteString(" (")
+		buf.WriteString(fmt.Sprintf("%T", err))
+		buf.WriteByte(')')
+
+		causes := unwrapErrors(err)
+		if depth >= maxErrorDepth {
+			causes = nil
+		}
+		switch len(causes) {
+		case 0:
+			err = nil
+		case 1:
+			buf.WriteString(" cause: ")
+			err = causes[0]
+		default:
+			buf.WriteString(" causes: [")
+			for i, cause := range causes {
+				if i > 0 {
+					buf.WriteString(", ")
+				}
+				t.encodeErrorChain(buf, cause, depth+1)
+			}
+			buf.WriteByte(']')
+			err = nil
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package golog
+
+import (
+	"bytes"
+	"sort"
+	"time"
+)
+
+// encodeComposite encode slices, maps and pointers of common types without reflection,
+// it returns false if the type is not supported.
+func (t *TypeEncoder) encodeComposite(buf *bytes.Buffer, val interface{}) bool {
+	switch v := val.(type) {
+	case []int:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeInt(buf, int64(v[i])) })
+	case []int8:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeInt(buf, int64(v[i])) })
+	case []int16:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeInt(buf, int64(v[i])) })
+	case []int32:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeInt(buf, int64(v[i])) })
+	case []int64:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeInt(buf, v[i]) })
+	case []uint:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeUint(buf, uint64(v[i])) })
+	case []uint16:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeUint(buf, uint64(v[i])) })
+	case []uint32:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeUint(buf, uint64(v[i])) })
+	case []uint64:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeUint(buf, v[i]) })
+	case []float32:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeFloat(buf, float64(v[i])) })
+	case []float64:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeFloat(buf, v[i]) })
+	case []bool:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeBool(buf, v[i]) })
+	case []string:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeString(buf, v[i]) })
+	case []time.Time:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeTime(buf, v[i]) })
+	case []time.Duration:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeDuration(buf, v[i]) })
+	case []error:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) {
+			if v[i] == nil {
+				t.EncodeNil(buf)
+			} else {
+				t.EncodeError(buf, v[i])
+			}
+		})
+	case []interface{}:
+		t.encodeSlice(buf, v == nil, len(v), func(i int) { t.EncodeVal(buf, v[i]) })
+	case map[string]interface{}:
+		if v == nil {
+			t.EncodeNil(buf)
+			break
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		t.encodeMap(buf, keys, func(i int) { t.EncodeVal(buf, v[keys[i]]) })
+	case map[string]string:
+		if v == nil {
+			t.EncodeNil(buf)
+			break
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		t.encodeMap(buf, keys, func(i int) { t.EncodeString(buf, v[keys[i]]) })
+	case *int:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeInt(buf, int64(*v))
+		}
+	case *int8:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeInt(buf, int64(*v))
+		}
+	case *int16:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeInt(buf, int64(*v))
+		}
+	case *int32:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeInt(buf, int64(*v))
+		}
+	case *int64:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeInt(buf, *v)
+		}
+	case *uint:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeUint(buf, uint64(*v))
+		}
+	case *uint8:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeUint(buf, uint64(*v))
+		}
+	case *uint16:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeUint(buf, uint64(*v))
+		}
+	case *uint32:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeUint(buf, uint64(*v))
+		}
+	case *uint64:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeUint(buf, *v)
+		}
+	case *float32:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeFloat(buf, float64(*v))
+		}
+	case *float64:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeFloat(buf, *v)
+		}
+	case *bool:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeBool(buf, *v)
+		}
+	case *string:
+		if v == nil {
+			t.EncodeNil(buf)
+		} else {
+			t.EncodeString(buf, *v)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+func (t *TypeEncoder) writeElemSeparator(buf *bytes.Buffer) {
+	if t.ElemSeparator != "" {
+		buf.WriteString(t.ElemSeparator)
+	} else {
+		buf.WriteByte(',')
+	}
+}
+
+// encodeKey encode key of object entries followed by the key separator.
+func (t *TypeEncoder) encodeKey(buf *bytes.Buffer, key string) {
+	if t.Key != nil {
+		t.Key(buf, key)
+		return
+	}
+	t.EncodeString(buf, key)
+	if t.KeySeparator != "" {
+		buf.WriteString(t.KeySeparator)
+	} else {
+		buf.WriteByte(':')
+	}
+}
+
+// encodeSlice encode n elements in brackets, nil slice is encoded as nil value.
+func (t *TypeEncoder) encodeSlice(buf *bytes.Buffer, isNil bool, n int, elem func(i int)) {
+	if isNil {
+		t.EncodeNil(buf)
+		return
+	}
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			t.writeElemSeparator(buf)
+		}
+		elem(i)
+	}
+	buf.WriteByte(']')
+}
+
+// encodeMap encode entries in braces, keys should be sorted for stable output.
+func (t *TypeEncoder) encodeMap(buf *bytes.Buffer, keys []string, value func(i int)) {
+	buf.WriteByte('{')
+	for i, key := range keys {
+		if i > 0 {
+			t.writeElemSeparator(buf)
+		}
+		t.encodeKey(buf, key)
+		value(i)
+	}
+	buf.WriteByte('}')
+}
@@ -3,64 +3,71 @@ package golog
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 )
 
 type JSONEncoder struct {
 	TypeEncoder
 }
 
+// NewJSONEncoder create a JSON encoder, []byte values are encoded as base64 strings,
+// set Bytes to nil to encode them as escaped strings instead.
 func NewJSONEncoder(timeformat string) Encoder {
 	if timeformat == "" {
 		timeformat = logDatetimeFmt
 	}
-	return &JSONEncoder{
+	enc := &JSONEncoder{
 		TypeEncoder: TypeEncoder{
 			Timeformat: timeformat,
 			Default: func(buf *bytes.Buffer, v interface{}) {
 				data, _ := json.Marshal(v)
 				buf.Write(data)
 			},
-			Bytes: func(buf *bytes.Buffer, b []byte) {
-				buf.Write(b)
-			},
 		},
 	}
+	enc.Bytes = enc.EncodeBytesBase64
+	enc.Error = enc.EncodeErrorObject
+	return enc
 }
 
-func (j *JSONEncoder) encodeKeyValue(buf *bytes.Buffer, key string, val interface{}) {
+func (j *JSONEncoder) writeKey(buf *bytes.Buffer, key string) {
 	j.EncodeString(buf, key)
 	buf.WriteByte(':')
-	j.EncodeVal(buf, val)
+}
+
+func (j *JSONEncoder) encodeField(buf *bytes.Buffer, f *Field) {
+	j.writeKey(buf, f.Key)
+	j.EncodeFieldValue(buf, f)
 }
 
 func (j *JSONEncoder) Encode(buf *bytes.Buffer, log *Log) error {
 	buf.WriteByte('{')
-	j.encodeKeyValue(buf, "level", log.Level.String())
+	j.writeKey(buf, "level")
+	j.EncodeString(buf, log.Level.String())
 	buf.WriteByte(',')
-	j.encodeKeyValue(buf, "time", log.Time)
+	j.writeKey(buf, "time")
+	j.EncodeTime(buf, log.Time)
 	buf.WriteByte(',')
-	j.encodeKeyValue(buf, "pos", log.Position)
-
-	var msg string
-	if log.Format != "" {
-		if log.Prefix != "" {
-			log.Format = log.Prefix + log.Format
-		}
-		msg = fmt.Sprintf(log.Format, log.Args...)
-	} else {
-		s := fmt.Sprintln(log.Args...)
-		if l := len(s); s[l-1] == '\n' {
-			s = s[:l-1]
-		}
-		msg = log.Prefix + s
-	}
+	j.writeKey(buf, "pos")
+	j.EncodeString(buf, log.Position)
 	buf.WriteByte(',')
-	j.encodeKeyValue(buf, "msg", msg)
+	j.writeKey(buf, "msg")
+	j.EncodeString(buf, log.message())
 
 	for i := range log.Fields {
 		buf.WriteString(`,`)
-		j.encodeKeyValue(buf, log.Fields[i].Key, log.Fields[i].Value)
+		j.encodeField(buf, &log.Fields[i])
+	}
+	if len(log.Stack) > 0 {
+		buf.WriteByte(',')
+		j.EncodeString(buf, "stack")
+		buf.WriteString(":[")
+		for i := range log.Stack {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			j.EncodeString(buf, log.Stack[i].String())
+		}
+		buf.WriteByte(']')
 	}
 	buf.WriteString("}\n")
 	return nil
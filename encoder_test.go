@@ -2,7 +2,10 @@ package golog
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -29,7 +32,7 @@ func TestJSONEncoder(t *testing.T) {
 		"E", time.Now(),
 		"G", `"aaa"`,
 		"C", []string{},
-		Field{"F", []int{1, 2, 3}},
+		Field{Key: "F", Value: []int{1, 2, 3}},
 	)
 
 	json.Encode(&buf, &log)
@@ -39,3 +42,325 @@ func TestJSONEncoder(t *testing.T) {
 	text.Encode(&buf, &log)
 	fmt.Print(buf.String())
 }
+
+func TestEncodeError(t *testing.T) {
+	root := errors.New("EOF")
+	wrapped := fmt.Errorf("read: %w", root)
+	joined := errors.Join(wrapped, errors.New("closed"))
+
+	var buf bytes.Buffer
+	json := NewJSONEncoder("").(*JSONEncoder)
+	json.EncodeVal(&buf, wrapped)
+	if s := buf.String(); s != `{"msg":"read: EOF","type":"*fmt.wrapError","cause":{"msg":"EOF","type":"*errors.errorString"}}` {
+		t.Errorf("unexpected json error: %s", s)
+	}
+	buf.Reset()
+	json.EncodeVal(&buf, joined)
+	if s := buf.String(); !strings.Contains(s, `"type":"*errors.joinError","causes":[{"msg":"read: EOF"`) ||
+		!strings.HasSuffix(s, `{"msg":"closed","type":"*errors.errorString"}]}`) {
+		t.Errorf("unexpected json joined error: %s", s)
+	}
+
+	buf.Reset()
+	text := NewTextEncoder("", "").(*TextEncoder)
+	text.EncodeVal(&buf, wrapped)
+	if s := buf.String(); s != `"read: EOF" (*fmt.wrapError) cause: "EOF" (*errors.errorString)` {
+		t.Errorf("unexpected text error: %s", s)
+	}
+	buf.Reset()
+	text.EncodeVal(&buf, errors.Join(root, wrapped))
+	if s := buf.String(); !strings.HasSuffix(s, ` (*errors.joinError) causes: ["EOF" (*errors.errorString), "read: EOF" (*fmt.wrapError) cause: "EOF" (*errors.errorString)]`) {
+		t.Errorf("unexpected text joined error: %s", s)
+	}
+}
+
+func TestEncodeStringEscape(t *testing.T) {
+	enc := NewJSONEncoder("").(*JSONEncoder)
+	for _, s := range []string{
+		`C:\Users\golog`,
+		"line1\nline2\r\n\ttabbed",
+		"ctrl \x00\x01\x1f\x7f",
+		`quote "q" <tag>&`,
+		"invalid \xff\xfe utf8 中文",
+		"sep \u2028\u2029",
+	} {
+		var buf bytes.Buffer
+		log := Log{Level: LevelInfo, Args: []interface{}{s}}
+		log.appendFields(s, s)
+		enc.Encode(&buf, &log)
+		line := buf.Bytes()
+		if !json.Valid(line) || bytes.Count(line, []byte("\n")) != 1 {
+			t.Fatalf("invalid json line for %q: %s", s, line)
+		}
+
+		var m map[string]interface{}
+		json.Unmarshal(line, &m)
+		expect := string([]rune(s))
+		if m["msg"] != expect || m[expect] != expect {
+			t.Errorf("%q: unexpected decoded string: %q", s, m["msg"])
+		}
+
+		buf.Reset()
+		enc.EncodeBytesWithQuote(&buf, []byte(s))
+		var decoded string
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded != expect {
+			t.Errorf("%q: unexpected decoded bytes: %q %v", s, decoded, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc.EscapeHTML = true
+	enc.EncodeStringWithQuote(&buf, "<a>&")
+	if s := buf.String(); s != `"\u003ca\u003e\u0026"` {
+		t.Errorf("unexpected html escaped string: %s", s)
+	}
+}
+
+func TestEncodeBytes(t *testing.T) {
+	valid := json.RawMessage("{\n  \"a\": [1, 2]\n}")
+	invalid := json.RawMessage(`{"a":`)
+	var (
+		nilRaw   *json.RawMessage
+		emptyRaw = json.RawMessage{}
+	)
+
+	var buf bytes.Buffer
+	enc := NewJSONEncoder("").(*JSONEncoder)
+	log := Log{Level: LevelInfo}
+	log.appendFields(
+		"bin", []byte{0, 0xff, '"'},
+		"raw", valid,
+		"rawptr", &valid,
+		"invalid", invalid,
+		"invalidptr", &invalid,
+		"nil", nilRaw,
+		"empty", json.RawMessage(nil),
+		"emptyptr", &emptyRaw,
+	)
+	enc.Encode(&buf, &log)
+	if !json.Valid(buf.Bytes()) {
+		t.Fatalf("invalid json line: %s", buf.Bytes())
+	}
+	expect := `"bin":"AP8i","raw":{"a":[1,2]},"rawptr":{"a":[1,2]},"invalid":"{\"a\":","invalidptr":"{\"a\":","nil":null,"empty":null,"emptyptr":null}`
+	if s := buf.String(); !strings.HasSuffix(s, expect+"\n") {
+		t.Errorf("unexpected encoded bytes: %s", s)
+	}
+
+	buf.Reset()
+	enc.Bytes = nil
+	enc.EncodeVal(&buf, []byte("a\nb"))
+	if s := buf.String(); s != `"a\nb"` {
+		t.Errorf("unexpected escaped bytes: %s", s)
+	}
+
+	buf.Reset()
+	NewTextEncoder("", "").(*TextEncoder).EncodeVal(&buf, (*json.RawMessage)(nil))
+	if s := buf.String(); s != "<nil>" {
+		t.Errorf("unexpected text of nil raw message: %s", s)
+	}
+}
+
+func TestEncodeComposite(t *testing.T) {
+	n := 3
+	s := "s"
+	var nilInt *int
+	values := []interface{}{
+		[]int{1, 2},
+		[]string{"a", `b"`},
+		[]string(nil),
+		[]float64{},
+		[]error{errors.New("e"), nil},
+		map[string]interface{}{"b": []int64{1}, "a": true, "c": nil},
+		&n,
+		&s,
+		nilInt,
+		nil,
+	}
+
+	var buf bytes.Buffer
+	json := NewJSONEncoder("").(*JSONEncoder)
+	json.Error = nil
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		json.EncodeVal(&buf, v)
+	}
+	if s := buf.String(); s != `[1,2] ["a","b\""] null [] ["e",null] {"a":true,"b":[1],"c":null} 3 "s" null null` {
+		t.Errorf("unexpected json values: %s", s)
+	}
+
+	buf.Reset()
+	text := NewTextEncoder("", "").(*TextEncoder)
+	text.Error = nil
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		text.EncodeVal(&buf, v)
+	}
+	if s := buf.String(); s != `[1 2] ["a" "b\""] <nil> [] ["e" <nil>] {a=true b=[1] c=<nil>} 3 "s" <nil> <nil>` {
+		t.Errorf("unexpected text values: %s", s)
+	}
+}
+
+func benchmarkEncodeVal(b *testing.B, v interface{}) {
+	enc := &NewJSONEncoder("").(*JSONEncoder).TypeEncoder
+	for _, bench := range []struct {
+		name   string
+		encode func(*bytes.Buffer, interface{})
+	}{
+		{"Native", enc.EncodeVal},
+		{"Default", enc.Default},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			var buf bytes.Buffer
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				bench.encode(&buf, v)
+			}
+		})
+	}
+}
+
+func BenchmarkEncodeSlice(b *testing.B) {
+	benchmarkEncodeVal(b, []int{1, 2, 3, 4, 5, 6, 7, 8})
+}
+
+func BenchmarkEncodeStrings(b *testing.B) {
+	benchmarkEncodeVal(b, []string{"alpha", "beta", "gamma", "delta"})
+}
+
+func BenchmarkEncodeMap(b *testing.B) {
+	benchmarkEncodeVal(b, map[string]interface{}{"id": 1, "name": "golog", "tags": []string{"a", "b"}})
+}
+
+func TestTypedFields(t *testing.T) {
+	tm := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	log := Log{
+		Fields: []Field{
+			String("s", "a"),
+			Int("i", -1),
+			Uint64("u", 2),
+			Float64("f", 1.5),
+			Bool("b", true),
+			Duration("d", time.Second),
+			Time("t", tm),
+			Err(errors.New("e")),
+			Any("any", []int{1}),
+		},
+	}
+
+	var buf bytes.Buffer
+	json := NewJSONEncoder("2006-01-02T15:04:05").(*JSONEncoder)
+	json.Error = nil
+	for i := range log.Fields {
+		buf.WriteByte(',')
+		json.encodeField(&buf, &log.Fields[i])
+	}
+	if s := buf.String(); s != `,"s":"a","i":-1,"u":2,"f":1.5000,"b":true,"d":"1s","t":"2020-01-02T03:04:05","error":"e","any":[1]` {
+		t.Errorf("unexpected json fields: %s", s)
+	}
+	if log.Fields[0].Value != nil || log.Fields[6].Value != nil {
+		t.Errorf("expect nil Value of typed fields")
+	}
+	if v := log.Fields[6].Interface(); v != tm {
+		t.Errorf("unexpected time value: %v", v)
+	}
+	if v := log.Fields[3].Interface(); v != 1.5 {
+		t.Errorf("unexpected float value: %v", v)
+	}
+
+	// durations and times are formatted to strings
+	fields := log.Fields[:5]
+	allocs := testing.AllocsPerRun(100, func() {
+		buf.Reset()
+		for i := range fields {
+			json.encodeField(&buf, &fields[i])
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("typed fields allocated %.0f times", allocs)
+	}
+}
+
+func BenchmarkFields(b *testing.B) {
+	logger := New(LevelDebug, 0, 1024, NewJSONEncoder(""))
+	defer logger.Close()
+
+	b.Run("WithFields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithFields("id", i, "name", "golog", "ok", true).Info("done")
+		}
+	})
+	b.Run("WithTypedFieldsInline", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.WithTypedFields(Int("id", i), String("name", "golog"), Bool("ok", true)).Info("done")
+		}
+	})
+	b.Run("WithTypedFields", func(b *testing.B) {
+		// fields passed through the Logger interface escape, reuse them to avoid allocation
+		fields := make([]Field, 3)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fields[0], fields[1], fields[2] = Int("id", i), String("name", "golog"), Bool("ok", true)
+			logger.WithTypedFields(fields...).Info("done")
+		}
+	})
+}
+
+type testUser struct {
+	ID    int64
+	Name  string
+	Roles testRoles
+	Err   error
+}
+
+func (u *testUser) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddInt64("id", u.ID)
+	enc.AddString("name", u.Name)
+	if err := enc.AddArray("roles", u.Roles); err != nil {
+		return err
+	}
+	enc.AddAny("tags", []string{"a"})
+	return u.Err
+}
+
+type testRoles []string
+
+func (r testRoles) MarshalLogArray(enc ArrayEncoder) error {
+	for _, role := range r {
+		enc.AppendString(role)
+	}
+	return nil
+}
+
+func TestMarshaler(t *testing.T) {
+	user := &testUser{ID: 1, Name: "golog", Roles: testRoles{"admin", "dev"}}
+	log := Log{Fields: []Field{Object("user", user), Array("roles", user.Roles)}}
+
+	var buf bytes.Buffer
+	json := NewJSONEncoder("").(*JSONEncoder)
+	json.Encode(&buf, &log)
+	if s := buf.String(); !strings.HasSuffix(s, `"user":{"id":1,"name":"golog","roles":["admin","dev"],"tags":["a"]},"roles":["admin","dev"]}`+"\n") {
+		t.Errorf("unexpected json: %s", s)
+	}
+
+	buf.Reset()
+	text := NewTextEncoder("", "").(*TextEncoder)
+	text.Encode(&buf, &log)
+	if s := buf.String(); !strings.HasSuffix(s, ` user={id=1 name="golog" roles=["admin" "dev"] tags=["a"]} roles=["admin" "dev"]`+"\n") {
+		t.Errorf("unexpected text: %s", s)
+	}
+
+	buf.Reset()
+	user.Err = errors.New("bad user")
+	json.Error = nil
+	json.EncodeVal(&buf, user)
+	if s := buf.String(); s != `"bad user"` {
+		t.Errorf("unexpected json of failed marshaler: %s", s)
+	}
+}
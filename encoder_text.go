@@ -3,6 +3,7 @@ package golog
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 )
 
 type TextEncoder struct {
@@ -17,45 +18,58 @@ func NewTextEncoder(timeformat, separator string) Encoder {
 	if separator == "" {
 		separator = "="
 	}
-	return &TextEncoder{
+	enc := &TextEncoder{
 		TypeEncoder: TypeEncoder{
 			Timeformat: timeformat,
 			Default: func(buf *bytes.Buffer, v interface{}) {
 				fmt.Fprintf(buf, "%v", v)
 			},
+			Null:          "<nil>",
+			ElemSeparator: " ",
+			KeySeparator:  separator,
 		},
 		Separator: separator,
 	}
+	enc.Error = enc.EncodeErrorChain
+	enc.Key = enc.writeKey
+	return enc
 }
-func (t *TextEncoder) encodeKeyValue(buf *bytes.Buffer, key string, val interface{}) {
+func (t *TextEncoder) writeKey(buf *bytes.Buffer, key string) {
 	buf.WriteString(key)
 	buf.WriteString(t.Separator)
-	t.EncodeVal(buf, val)
+}
+
+func (t *TextEncoder) encodeField(buf *bytes.Buffer, f *Field) {
+	t.writeKey(buf, f.Key)
+	t.EncodeFieldValue(buf, f)
 }
 
 func (t *TextEncoder) Encode(buf *bytes.Buffer, log *Log) error {
-	t.encodeKeyValue(buf, "level", log.Level.String())
+	t.writeKey(buf, "level")
+	t.EncodeString(buf, log.Level.String())
 	buf.WriteByte(' ')
-	t.encodeKeyValue(buf, "time", log.Time)
+	t.writeKey(buf, "time")
+	t.EncodeTime(buf, log.Time)
 	buf.WriteByte(' ')
-	t.encodeKeyValue(buf, "pos", log.Position)
-
-	var msg string
-	if log.Format != "" {
-		msg = fmt.Sprintf(log.Prefix+log.Format, log.Args...)
-	} else {
-		msg = log.Prefix + fmt.Sprintln(log.Args...)
-		if l := len(msg); l > 0 && msg[l-1] == '\n' {
-			msg = msg[:l-1]
-		}
-	}
+	t.writeKey(buf, "pos")
+	t.EncodeString(buf, log.Position)
 	buf.WriteByte(' ')
-	t.encodeKeyValue(buf, "msg", msg)
+	t.writeKey(buf, "msg")
+	t.EncodeString(buf, log.message())
 
 	for i := range log.Fields {
 		buf.WriteByte(' ')
-		t.encodeKeyValue(buf, log.Fields[i].Key, log.Fields[i].Value)
+		t.encodeField(buf, &log.Fields[i])
 	}
 	buf.WriteByte('\n')
+	for i := range log.Stack {
+		buf.WriteByte('\t')
+		buf.WriteString(log.Stack[i].Function)
+		buf.WriteString("\n\t\t")
+		buf.WriteString(log.Stack[i].File)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(log.Stack[i].Line))
+		buf.WriteByte('\n')
+	}
 	return nil
 }
@@ -0,0 +1,129 @@
+package golog
+
+import (
+	"math"
+	"time"
+)
+
+type fieldKind uint8
+
+const (
+	fieldAny fieldKind = iota
+	fieldString
+	fieldInt
+	fieldUint
+	fieldFloat
+	fieldBool
+	fieldDuration
+	fieldTime
+)
+
+// Field is a key-value pair attached to logs, fields created by typed constructors such as
+// String and Int64 store the value without boxing it into an interface and leave Value nil,
+// use Interface to get the value of any field.
+type Field struct {
+	Key   string
+	Value interface{}
+
+	kind fieldKind
+	num  int64
+	str  string
+	loc  *time.Location
+}
+
+// Interface returns the value of the field, integers are returned as int64 or uint64.
+func (f *Field) Interface() interface{} {
+	switch f.kind {
+	case fieldString:
+		return f.str
+	case fieldInt:
+		return f.num
+	case fieldUint:
+		return uint64(f.num)
+	case fieldFloat:
+		return math.Float64frombits(uint64(f.num))
+	case fieldBool:
+		return f.num != 0
+	case fieldDuration:
+		return time.Duration(f.num)
+	case fieldTime:
+		return f.time()
+	}
+	return f.Value
+}
+
+func (f *Field) time() time.Time {
+	t := time.Unix(0, f.num)
+	if f.loc != nil {
+		t = t.In(f.loc)
+	}
+	return t
+}
+
+func Any(key string, val interface{}) Field {
+	return Field{Key: key, Value: val}
+}
+
+func String(key, val string) Field {
+	return Field{Key: key, kind: fieldString, str: val}
+}
+
+func Int(key string, val int) Field {
+	return Field{Key: key, kind: fieldInt, num: int64(val)}
+}
+
+func Int64(key string, val int64) Field {
+	return Field{Key: key, kind: fieldInt, num: val}
+}
+
+func Uint(key string, val uint) Field {
+	return Field{Key: key, kind: fieldUint, num: int64(val)}
+}
+
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, kind: fieldUint, num: int64(val)}
+}
+
+func Float64(key string, val float64) Field {
+	return Field{Key: key, kind: fieldFloat, num: int64(math.Float64bits(val))}
+}
+
+func Bool(key string, val bool) Field {
+	f := Field{Key: key, kind: fieldBool}
+	if val {
+		f.num = 1
+	}
+	return f
+}
+
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, kind: fieldDuration, num: int64(val)}
+}
+
+// minTimeNano and maxTimeNano are the range of time can be represented in int64 nanoseconds.
+var (
+	minTimeNano = time.Unix(0, math.MinInt64)
+	maxTimeNano = time.Unix(0, math.MaxInt64)
+)
+
+// Time stores the time as nanoseconds and the location, times out of the range of int64
+// nanoseconds are stored as is.
+func Time(key string, val time.Time) Field {
+	if val.Before(minTimeNano) || val.After(maxTimeNano) {
+		return Field{Key: key, Value: val}
+	}
+	return Field{Key: key, kind: fieldTime, num: val.UnixNano(), loc: val.Location()}
+}
+
+// Err creates a field with key "error".
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+func Object(key string, val ObjectMarshaler) Field {
+	return Field{Key: key, Value: val}
+}
+
+func Array(key string, val ArrayMarshaler) Field {
+	return Field{Key: key, Value: val}
+}
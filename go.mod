@@ -1 +1,3 @@
 module github.com/cosiner/golog
+
+go 1.20
@@ -0,0 +1,82 @@
+package golog
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type levelHandler struct {
+	logger Logger
+	named  map[string]Logger
+}
+
+// LevelHandler create a http.Handler to inspect and change log levels. GET responds
+// current level as {"level":"INFO"}, PUT accepts a level in same format parsed by
+// ParseLevelStrict, the "logger" query parameter selects a logger from named loggers,
+// the default logger is used if it's empty. Without the parameter, GET on a handler
+// with named loggers also responds levels of them in a "loggers" object. The level
+// applies to packages matching no level rule, rules set by SetLevelRules are kept.
+func LevelHandler(logger Logger, named map[string]Logger) http.Handler {
+	return &levelHandler{
+		logger: logger,
+		named:  named,
+	}
+}
+
+type levelPayload struct {
+	Level   string            `json:"level"`
+	Loggers map[string]string `json:"loggers,omitempty"`
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("logger")
+	logger := h.logger
+	if name != "" {
+		logger = h.named[name]
+	}
+	if logger == nil {
+		h.writeError(w, http.StatusNotFound, "logger not found: "+name)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		payload := levelPayload{Level: logger.Level().String()}
+		if name == "" && len(h.named) > 0 {
+			payload.Loggers = make(map[string]string, len(h.named))
+			for n, l := range h.named {
+				payload.Loggers[n] = l.Level().String()
+			}
+		}
+		h.writeJSON(w, http.StatusOK, payload)
+	case http.MethodPut:
+		var payload levelPayload
+		err := json.NewDecoder(r.Body).Decode(&payload)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+			return
+		}
+		level, err := ParseLevelStrict(payload.Level)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		logger.SetLevel(level)
+		h.writeJSON(w, http.StatusOK, levelPayload{Level: level.String()})
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		h.writeError(w, http.StatusMethodNotAllowed, "method not allowed: "+r.Method)
+	}
+}
+
+func (h *levelHandler) writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func (h *levelHandler) writeError(w http.ResponseWriter, code int, msg string) {
+	h.writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{msg})
+}
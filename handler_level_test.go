@@ -0,0 +1,41 @@
+package golog
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLevelHandler(t *testing.T) {
+	def := New(LevelInfo, 0, 0, NewTextEncoder("", ""))
+	defer def.Close()
+	db := New(LevelWarn, 0, 0, NewTextEncoder("", ""))
+	defer db.Close()
+	h := LevelHandler(def, map[string]Logger{"db": db})
+
+	for _, c := range []struct {
+		Method string
+		URL    string
+		Body   string
+		Code   int
+		Resp   string
+	}{
+		{"GET", "/", "", 200, `{"level":"INFO","loggers":{"db":"WARN"}}`},
+		{"GET", "/?logger=db", "", 200, `{"level":"WARN"}`},
+		{"PUT", "/?logger=db", `{"level":"debug"}`, 200, `{"level":"DEBUG"}`},
+		{"PUT", "/", `{"level":"DEBGU"}`, 400, `{"error":"golog: unknown level: \"DEBGU\""}`},
+		{"PUT", "/", `{"level":"err"}`, 200, `{"level":"ERROR"}`},
+		{"GET", "/", "", 200, `{"level":"ERROR","loggers":{"db":"DEBUG"}}`},
+		{"GET", "/?logger=none", "", 404, `{"error":"logger not found: none"}`},
+		{"POST", "/", "", 405, `{"error":"method not allowed: POST"}`},
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(c.Method, c.URL, strings.NewReader(c.Body)))
+		if rec.Code != c.Code || strings.TrimSpace(rec.Body.String()) != c.Resp {
+			t.Errorf("%s %s: expect %d %s, got %d %s", c.Method, c.URL, c.Code, c.Resp, rec.Code, rec.Body.String())
+		}
+	}
+	if def.Level() != LevelError || db.Level() != LevelDebug {
+		t.Fatal("levels are not changed")
+	}
+}
@@ -0,0 +1,136 @@
+package golog
+
+import (
+	"errors"
+	"net/url"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+type (
+	levelRule struct {
+		pattern string
+		prefix  bool
+		level   Level
+	}
+
+	// levelRules overrides level of logs by package of the caller.
+	levelRules struct {
+		rules []levelRule
+		// level of rule "*", it's applied as the logger level
+		def    Level
+		hasDef bool
+		min    Level
+		// caller pc -> index of matched rule, -1 for none
+		cache sync.Map
+	}
+)
+
+// parseLevelRules parse comma-separated rules in format "pattern=LEVEL", the pattern is
+// a package path such as "github.com/org/db", a package path ends with "/*" to match the
+// package and all sub-packages, or "*" to match all packages.
+func parseLevelRules(s string) (*levelRules, error) {
+	r := &levelRules{
+		min: levelMax,
+	}
+	for _, sec := range strings.Split(s, ",") {
+		sec = strings.TrimSpace(sec)
+		if sec == "" {
+			continue
+		}
+		i := strings.LastIndexByte(sec, '=')
+		if i < 0 {
+			return nil, errors.New("golog: invalid level rule: " + sec)
+		}
+		pattern, name := strings.TrimSpace(sec[:i]), strings.TrimSpace(sec[i+1:])
+		level, err := ParseLevelStrict(name)
+		if pattern == "" || err != nil {
+			return nil, errors.New("golog: invalid level rule: " + sec)
+		}
+
+		rule := levelRule{pattern: pattern, level: level}
+		switch {
+		case pattern == "*":
+			r.def, r.hasDef = level, true
+			continue
+		case strings.HasSuffix(pattern, "/*"):
+			rule.pattern = pattern[:len(pattern)-2]
+			rule.prefix = true
+		}
+		r.rules = append(r.rules, rule)
+		if level < r.min {
+			r.min = level
+		}
+	}
+	return r, nil
+}
+
+// match returns index of the most specific rule matches package pkg, or -1.
+func (r *levelRules) match(pkg string) int {
+	var (
+		index = -1
+		score = 0
+	)
+	for i, rule := range r.rules {
+		var s int
+		switch {
+		case rule.pattern == pkg:
+			s = 2*len(rule.pattern) + 1
+		case rule.prefix && strings.HasPrefix(pkg, rule.pattern) && pkg[len(rule.pattern)] == '/':
+			s = 2 * len(rule.pattern)
+		default:
+			continue
+		}
+		if s > score {
+			index, score = i, s
+		}
+	}
+	return index
+}
+
+// level returns the level for caller at pc, global is used if there is no rule matched.
+func (r *levelRules) level(pc uintptr, global Level) Level {
+	var index int
+	if v, ok := r.cache.Load(pc); ok {
+		index = v.(int)
+	} else {
+		index = r.match(callerPackage(pc))
+		r.cache.Store(pc, index)
+	}
+	if index < 0 {
+		return global
+	}
+	return r.rules[index].level
+}
+
+// minLevel returns the minimum level may be logged by any caller.
+func (r *levelRules) minLevel(global Level) Level {
+	if global < r.min {
+		return global
+	}
+	return r.min
+}
+
+func callerPackage(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	return funcPackage(fn.Name())
+}
+
+// funcPackage returns the package path of the function name, dots and special characters
+// in the last element of the path are escaped by the runtime, such as "gopkg.in/yaml%2ev3".
+func funcPackage(name string) string {
+	start := strings.LastIndexByte(name, '/') + 1
+	if i := strings.IndexByte(name[start:], '.'); i >= 0 {
+		name = name[:start+i]
+	}
+	if strings.IndexByte(name, '%') >= 0 {
+		if pkg, err := url.PathUnescape(name); err == nil {
+			return pkg
+		}
+	}
+	return name
+}
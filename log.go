@@ -4,28 +4,37 @@ import (
 	"fmt"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-type Level uint8
+type Level int8
 
 const (
-	LevelDebug Level = iota
+	// LevelTrace is below LevelDebug, the values of other levels are kept unchanged
+	LevelTrace Level = iota - 1
+	LevelDebug
 	LevelInfo
 	LevelWarn
 	LevelError
 	LevelPanic
 	LevelFatal
 
-	levelMin = LevelDebug
+	levelMin = LevelTrace
 	levelMax = LevelFatal
+	// count of levels, level l is indexed by l-levelMin in arrays of levels
+	levelCount = levelMax - levelMin + 1
 
 	logDatetimeFmt = "20060102150405"
 )
 
 func (l Level) String() string {
 	switch l {
+	case LevelTrace:
+		return "TRACE"
 	case LevelDebug:
 		return "DEBUG"
 	case LevelInfo:
@@ -45,24 +54,77 @@ func (l Level) String() string {
 
 // ParseLevel parse level from string regardless of string case, if match nothing, LevelInfo was returned.
 func ParseLevel(level string) Level {
-	level = strings.ToUpper(strings.TrimSpace(level))
+	l, err := ParseLevelStrict(level)
+	if err != nil {
+		return LevelInfo
+	}
+	return l
+}
+
+var levelAliases = map[string]Level{
+	"WARNING":  LevelWarn,
+	"ERR":      LevelError,
+	"CRITICAL": LevelFatal,
+}
+
+// ParseLevelStrict parse level from string regardless of string case, aliases "warning",
+// "err", "critical" and numeric values are also accepted, if match nothing,
+// an error was returned.
+func ParseLevelStrict(level string) (Level, error) {
+	name := strings.ToUpper(strings.TrimSpace(level))
 	for l := levelMin; l <= levelMax; l++ {
-		if level == l.String() {
-			return l
+		if name == l.String() {
+			return l, nil
 		}
 	}
+	if l, ok := levelAliases[name]; ok {
+		return l, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= int(levelMin) && n <= int(levelMax) {
+		return Level(n), nil
+	}
+	return LevelInfo, fmt.Errorf("golog: unknown level: %q", level)
+}
 
-	return LevelInfo
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
 }
 
-type (
-	Field struct {
-		Key   string
-		Value interface{}
+func (l *Level) UnmarshalText(text []byte) error {
+	return l.Set(string(text))
+}
+
+// Set implements flag.Value.
+func (l *Level) Set(s string) error {
+	level, err := ParseLevelStrict(s)
+	if err != nil {
+		return err
 	}
+	*l = level
+	return nil
+}
+
+// LevelVar is a Level variable safe for concurrent use, the zero value is LevelDebug.
+type LevelVar struct {
+	v int32
+}
 
+func (v *LevelVar) Level() Level {
+	return Level(atomic.LoadInt32(&v.v))
+}
+
+func (v *LevelVar) Set(level Level) {
+	atomic.StoreInt32(&v.v, int32(level))
+}
+
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
+
+type (
 	Log struct {
 		logger Logger
+		pc     uintptr
 
 		Level    Level
 		Time     time.Time
@@ -72,9 +134,26 @@ type (
 		Format string
 		Args   []interface{}
 		Fields []Field
+		Stack  []Frame
 	}
 )
 
+// PanicError is the panic value of LevelPanic logs if the PanicMode of logger is PanicTyped.
+type PanicError struct {
+	Message string
+	Fields  []Field
+	// the first error in fields or arguments of the log
+	Err error
+}
+
+func (e *PanicError) Error() string {
+	return e.Message
+}
+
+func (e *PanicError) Unwrap() error {
+	return e.Err
+}
+
 func (log *Log) panicInfo() string {
 	if log.Format == "" {
 		return fmt.Sprint(log.Args...)
@@ -82,29 +161,71 @@ func (log *Log) panicInfo() string {
 	return fmt.Sprintf(log.Format, log.Args...)
 }
 
+func (log *Log) panicError() *PanicError {
+	e := &PanicError{
+		Message: log.panicInfo(),
+	}
+	if len(log.Fields) > 0 {
+		e.Fields = append([]Field(nil), log.Fields...)
+	}
+	for i := range log.Fields {
+		if err, ok := log.Fields[i].Interface().(error); ok {
+			e.Err = err
+			return e
+		}
+	}
+	for _, arg := range log.Args {
+		if err, ok := arg.(error); ok {
+			e.Err = err
+			return e
+		}
+	}
+	return e
+}
+
+// message returns the formatted message with prefix.
+func (log *Log) message() string {
+	if log.Format != "" {
+		return fmt.Sprintf(log.Prefix+log.Format, log.Args...)
+	}
+	if len(log.Args) == 1 {
+		if s, ok := log.Args[0].(string); ok {
+			return log.Prefix + s
+		}
+	}
+	s := fmt.Sprintln(log.Args...)
+	return log.Prefix + s[:len(s)-1]
+}
+
 func (log *Log) appendField(key string, val interface{}) *Log {
 	log.Fields = append(log.Fields, Field{Key: key, Value: val})
 	return log
 }
 
 func (log *Log) appendFields(args ...interface{}) *Log {
+	log.Fields = appendFields(log.Fields, args...)
+	return log
+}
+
+// appendFields append key-value pairs and Fields in args to fields.
+func appendFields(fields []Field, args ...interface{}) []Field {
 	l := len(args)
 	for i := 0; i < l; {
 		arg := args[i]
 		switch arg := arg.(type) {
 		case string:
 			if i < l-1 {
-				log.appendField(arg, args[i+1])
+				fields = append(fields, Field{Key: arg, Value: args[i+1]})
 			}
 			i += 2
 		case Field:
-			log.Fields = append(log.Fields, arg)
+			fields = append(fields, arg)
 			i++
 		default:
 			i += 2
 		}
 	}
-	return log
+	return fields
 }
 
 func (l *Log) Depth(level Level, depth int, args ...interface{}) {
@@ -113,13 +234,18 @@ func (l *Log) Depth(level Level, depth int, args ...interface{}) {
 
 func (l *Log) Depthf(level Level, depth int, format string, args ...interface{}) {
 	l.Level = level
-	l.Position = callerPos(depth + 1)
+	l.pc, l.Position = caller(depth + 1)
 	l.Format = format
-	l.Args = args
+	// args are copied to the pooled slice so the caller's slice doesn't escape
+	l.Args = append(l.Args[:0], args...)
 
 	l.logger.Write(l)
 }
 
+func (l *Log) Tracef(format string, args ...interface{}) {
+	l.Depthf(LevelTrace, 1, format, args...)
+}
+
 func (l *Log) Debugf(format string, args ...interface{}) {
 	l.Depthf(LevelDebug, 1, format, args...)
 }
@@ -144,6 +270,10 @@ func (l *Log) Fatalf(format string, args ...interface{}) {
 	l.Depthf(LevelFatal, 1, format, args...)
 }
 
+func (l *Log) Trace(args ...interface{}) {
+	l.Depth(LevelTrace, 1, args...)
+}
+
 func (l *Log) Debug(args ...interface{}) {
 	l.Depth(LevelDebug, 1, args...)
 }
@@ -185,11 +315,43 @@ func lastIndexFuncN(s string, fn func(rune) bool, n int) int {
 }
 
 func callerPos(depth int) string {
+	_, pos := caller(depth + 1)
+	return pos
+}
+
+// positions caches formatted positions by pc of callers.
+var positions = struct {
+	sync.RWMutex
+	m map[uintptr]string
+}{m: make(map[uintptr]string)}
+
+// caller returns the pc and position of the caller, it's same as runtime.Caller but
+// resolves file and line only once for each pc.
+func caller(depth int) (uintptr, string) {
+	var pcs [1]uintptr
+	if runtime.Callers(depth+2, pcs[:]) == 0 {
+		return 0, ""
+	}
+	pc := pcs[0]
+	positions.RLock()
+	pos, ok := positions.m[pc]
+	positions.RUnlock()
+	if !ok {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		pos = formatPos(frame.File, frame.Line)
+		positions.Lock()
+		positions.m[pc] = pos
+		positions.Unlock()
+	}
+	// same as the pc returned by runtime.Caller, which is the call instruction
+	return pc - 1, pos
+}
+
+func formatPos(file string, line int) string {
 	const Level = 3
-	_, file, line, _ := runtime.Caller(depth + 1)
 	end := lastIndexFuncN(file, isPathSeparator, Level)
 	if end >= 0 {
 		file = file[end+1:]
 	}
-	return fmt.Sprintf("%s:%d", file, line)
+	return file + ":" + strconv.Itoa(line)
 }
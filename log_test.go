@@ -1,6 +1,16 @@
 package golog
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -51,3 +61,654 @@ func TestFileLog(t *testing.T) {
 	logger.Debug("DDDDDDDDDDDDDDDD")
 	time.Sleep(100 * time.Millisecond)
 }
+
+type testWriter struct {
+	mu      sync.Mutex
+	lines   []string
+	flushed int
+	closed  bool
+}
+
+func (w *testWriter) Write(level Level, bytes []byte) error {
+	w.mu.Lock()
+	w.lines = append(w.lines, string(bytes))
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *testWriter) Flush() {
+	w.mu.Lock()
+	w.flushed++
+	w.mu.Unlock()
+}
+
+func (w *testWriter) Close() {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
+}
+
+func (w *testWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.lines...)
+}
+
+func TestAsyncWrite(t *testing.T) {
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 4, NewTextEncoder("", "")).AddWriter(w)
+
+	const n = 100
+	for i := 0; i < n; i++ {
+		logger.Infof("%d", i)
+	}
+	logger.Flush()
+	lines := w.Lines()
+	if len(lines) != n {
+		t.Fatalf("expect %d lines after flush, got %d", n, len(lines))
+	}
+	for i, line := range lines {
+		if !strings.Contains(line, fmt.Sprintf(`msg="%d"`, i)) {
+			t.Fatalf("line %d out of order: %s", i, line)
+		}
+	}
+
+	logger.Info("last")
+	logger.Close()
+	if lines = w.Lines(); len(lines) != n+1 || !w.closed {
+		t.Fatalf("expect queue drained and writer closed on Close, got %d lines", len(lines))
+	}
+	logger.Info("after close")
+	logger.Flush()
+	if lines = w.Lines(); len(lines) != n+1 {
+		t.Fatal("logs after Close should be discarded")
+	}
+}
+
+func TestNoPeriodicFlush(t *testing.T) {
+	w := &testWriter{}
+	logger := New(LevelDebug, -1, 0, NewTextEncoder("", "")).AddWriter(w)
+	logger.Info("a")
+	logger.Close()
+	if lines := w.Lines(); len(lines) != 1 {
+		t.Fatalf("expect 1 line with negative flush seconds, got %d", len(lines))
+	}
+}
+
+type blockingWriter struct {
+	testWriter
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{
+		entered: make(chan struct{}, 1),
+		release: make(chan struct{}),
+	}
+}
+
+func (w *blockingWriter) Write(level Level, bytes []byte) error {
+	select {
+	case w.entered <- struct{}{}:
+		<-w.release
+	default:
+	}
+	return w.testWriter.Write(level, bytes)
+}
+
+func TestOverflowPolicy(t *testing.T) {
+	for _, c := range []struct {
+		Policy OverflowPolicy
+		Kept   []string
+	}{
+		{OverflowDropNewest, []string{"0", "1", "2"}},
+		{OverflowDropOldest, []string{"0", "8", "9"}},
+	} {
+		w := newBlockingWriter()
+		logger := NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+			Backlog:  2,
+			Overflow: c.Policy,
+		}).AddWriter(w)
+
+		logger.Info("0")
+		<-w.entered
+		for i := 1; i < 10; i++ {
+			logger.Infof("%d", i)
+		}
+		close(w.release)
+		logger.Close()
+
+		lines := w.Lines()
+		if len(lines) != len(c.Kept)+1 {
+			t.Fatalf("policy %d: expect %d lines, got %d", c.Policy, len(c.Kept)+1, len(lines))
+		}
+		for i, msg := range c.Kept {
+			if !strings.Contains(lines[i], fmt.Sprintf(`msg="%s"`, msg)) {
+				t.Errorf("policy %d: expect message %s, got %s", c.Policy, msg, lines[i])
+			}
+		}
+		if report := lines[len(c.Kept)]; !strings.Contains(report, `pos="" msg="7 entries dropped" INFO=7`) {
+			t.Errorf("policy %d: unexpected drop report: %s", c.Policy, report)
+		}
+	}
+}
+
+type errWriter struct {
+	testWriter
+}
+
+func (w *errWriter) Write(level Level, bytes []byte) error {
+	return errors.New("disk full")
+}
+
+func TestWriterErrors(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		errs   []error
+		failed = &errWriter{}
+		ok     = &testWriter{}
+	)
+	logger := NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+		ErrorHandler: func(w Writer, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if w != failed {
+				t.Errorf("unexpected writer %T", w)
+			}
+			errs = append(errs, err)
+		},
+	}).AddWriter(failed).AddWriter(ok)
+
+	logger.Info("a")
+	logger.Info("b")
+	logger.Flush()
+	if n := logger.WriterErrors(failed); n != 2 || len(errs) != 2 {
+		t.Fatalf("expect 2 errors, got %d counted, %d handled", n, len(errs))
+	}
+	if n := logger.WriterErrors(ok); n != 0 || len(ok.Lines()) != 2 {
+		t.Fatal("error of one writer should not affect others")
+	}
+	logger.Close()
+}
+
+func TestErrorHandlerAddWriter(t *testing.T) {
+	var (
+		logger Logger
+		added  = &testWriter{}
+		once   sync.Once
+	)
+	logger = NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+		ErrorHandler: func(w Writer, err error) {
+			once.Do(func() {
+				logger.AddWriter(added)
+				logger.Info("writer added")
+			})
+		},
+	}).AddWriter(&errWriter{})
+
+	logger.Info("a")
+	logger.Flush()
+	logger.Flush()
+	if lines := added.Lines(); len(lines) != 1 || !strings.Contains(lines[0], "writer added") {
+		t.Fatalf("expect log from error handler written, got %v", lines)
+	}
+	logger.Close()
+}
+
+func TestContextLogging(t *testing.T) {
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	defer logger.Close()
+
+	ctx := ContextWithFields(context.Background(), "request", 1)
+	child := ContextWithFields(ctx, "tenant", "a")
+	ctx = NewContext(child, logger)
+	if FromContext(context.Background()) != DefaultLogger || FromContext(ctx) != logger {
+		t.Fatal("unexpected logger from context")
+	}
+	if len(ContextFields(ctx)) != 2 || len(ContextFields(ContextWithFields(ctx, "x", 1))) != 3 {
+		t.Fatal("unexpected context fields")
+	}
+
+	InfoContext(ctx, "a")
+	logger.WarnContext(context.Background(), "b")
+	WithContext(ctx).Errorf("%s", "c")
+	logger.Flush()
+
+	lines := w.Lines()
+	if len(lines) != 3 {
+		t.Fatalf("expect 3 lines, got %d", len(lines))
+	}
+	for i, expect := range []string{
+		`msg="a" request=1 tenant="a"` + "\n",
+		`msg="b"` + "\n",
+		`msg="c" request=1 tenant="a"` + "\n",
+	} {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("expect %s in %s", expect, lines[i])
+		}
+		if !strings.Contains(lines[i], "/log_test.go:") {
+			t.Errorf("unexpected position: %s", lines[i])
+		}
+	}
+}
+
+func TestChildLogger(t *testing.T) {
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	defer logger.Close()
+
+	child := logger.With("component", "db")
+	grandchild := child.With(Field{Key: "table", Value: "user"})
+	child.Info("a")
+	grandchild.WithField("id", 1).Info("b")
+	child.Info("c")
+	logger.Info("d")
+	logger.Flush()
+
+	lines := w.Lines()
+	for i, expect := range []string{
+		`msg="a" component="db"`,
+		`msg="b" component="db" table="user" id=1`,
+		`msg="c" component="db"`,
+		`msg="d"`,
+	} {
+		if !strings.HasSuffix(lines[i], expect+"\n") {
+			t.Errorf("expect %s in %s", expect, lines[i])
+		}
+	}
+}
+
+func TestLoggerFamily(t *testing.T) {
+	w1, w2 := &testWriter{}, &testWriter{}
+	parent := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w1)
+	prefixed := parent.Prefix("[P] ")
+	child := prefixed.With("k", "v")
+
+	child.AddWriter(w2)
+	parent.Info("a")
+	prefixed.Info("b")
+	child.Info("c")
+	prefixed.Flush()
+
+	for _, w := range []*testWriter{w1, w2} {
+		lines := w.Lines()
+		if len(lines) != 3 {
+			t.Fatalf("expect 3 lines in each writer, got %d", len(lines))
+		}
+		if !strings.HasSuffix(lines[2], `msg="[P] c" k="v"`+"\n") {
+			t.Errorf("unexpected line: %s", lines[2])
+		}
+	}
+
+	child.Close()
+	parent.Info("d")
+	prefixed.Close()
+	if len(w1.Lines()) != 3 || !w1.closed || !w2.closed {
+		t.Fatal("closing a child should close the whole family")
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	w := &testWriter{}
+	parent := New(LevelWarn, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	defer parent.Close()
+	child := parent.Prefix("[C] ").With("k", 1)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			child.Debug("concurrent")
+		}
+	}()
+	child.SetLevel(LevelDebug)
+	wg.Wait()
+	if parent.Level() != LevelDebug {
+		t.Fatal("level change of child should apply to parent")
+	}
+
+	parent.Debug("a")
+	parent.SetLevel(LevelError)
+	child.Warn("b")
+	parent.Flush()
+	if lines := w.Lines(); len(lines) == 0 || !strings.Contains(lines[len(lines)-1], `msg="a"`) {
+		t.Fatal("unexpected logs after level changes")
+	}
+}
+
+func TestLevelRules(t *testing.T) {
+	r, err := parseLevelRules("github.com/org/db/*=DEBUG, github.com/org/db/cache=ERROR, *=WARN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for pkg, level := range map[string]Level{
+		"github.com/org/db":       LevelDebug,
+		"github.com/org/db/sql":   LevelDebug,
+		"github.com/org/db/cache": LevelError,
+		"github.com/org/dbx":      LevelWarn,
+		"main":                    LevelWarn,
+	} {
+		got := r.def
+		if i := r.match(pkg); i >= 0 {
+			got = r.rules[i].level
+		}
+		if got != level {
+			t.Errorf("%s: expect %s, got %s", pkg, level, got)
+		}
+	}
+	for name, pkg := range map[string]string{
+		"github.com/org/db.(*DB).Query": "github.com/org/db",
+		"gopkg.in/yaml%2ev3.Unmarshal":  "gopkg.in/yaml.v3",
+		"main.main.func1":               "main",
+	} {
+		if p := funcPackage(name); p != pkg {
+			t.Errorf("%s: expect package %s, got %s", name, pkg, p)
+		}
+	}
+	if r, _ := parseLevelRules("gopkg.in/yaml.v3=DEBUG, *=WARN"); r.rules[r.match(funcPackage("gopkg.in/yaml%2ev3.(*decoder).unmarshal"))].level != LevelDebug {
+		t.Error("expect rule matches package with dot in last path element")
+	}
+	for _, rules := range []string{"DEBUG", "a=DEBGU", "=INFO"} {
+		if _, err := parseLevelRules(rules); err == nil {
+			t.Errorf("expect error for rules %s", rules)
+		}
+	}
+
+	w := &testWriter{}
+	logger := New(LevelError, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	defer logger.Close()
+	if err := logger.SetLevelRules("github.com/cosiner/golog=DEBUG"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("a")
+	logger.WithField("k", 1).Debug("b")
+	if err := logger.SetLevelRules("github.com/other/*=DEBUG, *=WARN"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("c")
+	logger.Warn("d")
+	if logger.Level() != LevelWarn {
+		t.Fatalf("expect rule * changes logger level, got %s", logger.Level())
+	}
+	logger.SetLevel(LevelError)
+	logger.Warn("e")
+	logger.SetLevelRules("")
+	logger.Error("f")
+	logger.Flush()
+
+	var msgs []string
+	for _, line := range w.Lines() {
+		msgs = append(msgs, line[strings.Index(line, "msg="):len(line)-1])
+	}
+	if s := strings.Join(msgs, ","); s != `msg="a",msg="b" k=1,msg="d",msg="f"` {
+		t.Fatalf("unexpected logs: %s", s)
+	}
+}
+
+func TestParseLevelStrict(t *testing.T) {
+	for s, level := range map[string]Level{
+		"debug":     LevelDebug,
+		" Warn ":    LevelWarn,
+		"warning":   LevelWarn,
+		"ERR":       LevelError,
+		"trace":     LevelTrace,
+		"critical":  LevelFatal,
+		"3":         LevelError,
+		"0":         LevelDebug,
+		"-1":        LevelTrace,
+		"panic":     LevelPanic,
+		"fatal":     LevelFatal,
+		"info":      LevelInfo,
+		"Critical ": LevelFatal,
+	} {
+		l, err := ParseLevelStrict(s)
+		if err != nil || l != level {
+			t.Errorf("%s: expect %s, got %s %v", s, level, l, err)
+		}
+	}
+	for _, s := range []string{"DEBGU", "", "-2", "100"} {
+		if _, err := ParseLevelStrict(s); err == nil {
+			t.Errorf("%s: expect error", s)
+		}
+		if ParseLevel(s) != LevelInfo {
+			t.Errorf("%s: expect LevelInfo", s)
+		}
+	}
+
+	var conf struct {
+		Level Level `json:"level"`
+	}
+	if err := json.Unmarshal([]byte(`{"level":"warning"}`), &conf); err != nil || conf.Level != LevelWarn {
+		t.Fatalf("unmarshal level failed: %v", err)
+	}
+	if data, _ := json.Marshal(conf); string(data) != `{"level":"WARN"}` {
+		t.Fatalf("unexpected marshaled level: %s", data)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"DEBGU"}`), &conf); err == nil {
+		t.Fatal("expect error for unknown level")
+	}
+
+	level := LevelInfo
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "level", "log level")
+	if err := fs.Parse([]string{"-level", "error"}); err != nil || level != LevelError {
+		t.Fatalf("parse level flag failed: %v", err)
+	}
+}
+
+func TestTraceLevel(t *testing.T) {
+	var opts LoggerOptions
+	if opts.Level != LevelDebug || opts.StackLevel != LevelDebug || LevelTrace >= LevelDebug {
+		t.Fatal("zero value of levels should be LevelDebug and LevelTrace is below it")
+	}
+
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 0, NewJSONEncoder("")).AddWriter(w)
+	defer logger.Close()
+
+	logger.Trace("a")
+	logger.SetLevel(LevelTrace)
+	logger.Tracef("%s", "b")
+	logger.WithField("k", 1).Trace("c")
+	logger.TraceContext(context.Background(), "d")
+	logger.Flush()
+	lines := w.Lines()
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], `{"level":"TRACE"`) || !strings.Contains(lines[0], `"msg":"b"`) {
+		t.Fatalf("unexpected trace logs: %v", lines)
+	}
+
+	dir := t.TempDir()
+	mw, err := MultiFile(LevelTrace, FileLogOptions{LogDir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mw.Write(LevelTrace, []byte("trace\n"))
+	mw.Close()
+	data, err := ioutil.ReadFile(filepath.Join(dir, "TRACE."+time.Now().Format(logFileDateFmt)+".log"))
+	if err != nil || string(data) != "trace\n" {
+		t.Fatalf("unexpected trace log file: %q %v", data, err)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	w := &testWriter{}
+	logger := NewWithOptions(NewJSONEncoder(""), LoggerOptions{
+		StackLevel:  LevelError,
+		StackFrames: 2,
+	}).AddWriter(w)
+	defer logger.Close()
+	text := NewTextEncoder("", "")
+
+	logger.Info("a")
+	logger.WithField("k", 1).Error("b")
+	logger.Flush()
+	lines := w.Lines()
+	if strings.Contains(lines[0], `"stack"`) {
+		t.Fatalf("unexpected stack for info log: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"stack":["github.com/cosiner/golog.TestStackTrace `) ||
+		strings.Count(lines[1], "log_test.go:") != 2 || strings.Count(lines[1], ".go:") != 3 {
+		t.Fatalf("unexpected stack: %s", lines[1])
+	}
+
+	pc, _ := caller(0)
+	log := Log{Level: LevelError, Args: []interface{}{"c"}}
+	log.Stack = captureStack(log.Stack, 1, pc)
+	var buf bytes.Buffer
+	text.Encode(&buf, &log)
+	s := buf.String()
+	if !strings.Contains(s, "msg=\"c\"\n\tgithub.com/cosiner/golog.TestStackTrace\n\t\t") ||
+		!strings.Contains(s, "/log_test.go:") {
+		t.Fatalf("unexpected text stack: %s", s)
+	}
+	if stack := captureStack(nil, 1, 0); len(stack) != 1 || stack[0].Function != "testing.tRunner" {
+		t.Fatalf("expect golog frames trimmed without caller, got %v", stack)
+	}
+
+	w = &testWriter{}
+	logger = NewWithOptions(NewJSONEncoder(""), LoggerOptions{StackFrames: 1}).AddWriter(w)
+	defer logger.Close()
+	logger.Warn("d")
+	logger.Error("e")
+	logger.Flush()
+	if lines = w.Lines(); len(lines) != 2 || strings.Contains(lines[0], `"stack"`) || !strings.Contains(lines[1], `"stack"`) {
+		t.Fatalf("expect stack level defaults to ERROR, got %v", lines)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	defer logger.Close()
+
+	func() {
+		defer logger.Recover(LevelError, false)
+		var m map[string]int
+		m["a"] = 1
+	}()
+	lines := w.Lines()
+	if len(lines) != 1 {
+		t.Fatalf("expect panic logged and flushed, got %d lines", len(lines))
+	}
+	first := strings.SplitN(lines[0], "\n", 2)
+	if !strings.HasPrefix(first[0], `level="ERROR"`) || !strings.Contains(first[0], `msg="recovered from panic" panic="assignment to entry in nil map"`) {
+		t.Fatalf("unexpected panic log: %s", first[0])
+	}
+	if !strings.Contains(first[0], "log_test.go:") || !strings.HasPrefix(first[1], "\tgithub.com/cosiner/golog.TestRecover.func1\n") {
+		t.Fatalf("unexpected panic position or stack: %s", lines[0])
+	}
+
+	var exited bool
+	fatal := NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+		ExitFunc: func(int) { exited = true },
+	}).AddWriter(w)
+	defer fatal.Close()
+	for _, level := range []Level{LevelPanic, LevelFatal} {
+		func() {
+			defer fatal.Recover(level, false)
+			panic("boom")
+		}()
+	}
+	if lines = w.Lines(); exited || len(lines) != 3 || !strings.HasPrefix(lines[2], `level="FATAL"`) {
+		t.Fatalf("expect panic and fatal levels logged without panic or exit, got %d lines", len(lines))
+	}
+
+	defer func() {
+		if r := recover(); r != "again" {
+			t.Fatalf("expect repanic, got %v", r)
+		}
+	}()
+	defer logger.Recover(LevelWarn, true)
+	panic("again")
+}
+
+func TestFatal(t *testing.T) {
+	var (
+		code  int
+		calls []string
+	)
+	w1, w2 := &testWriter{}, &testWriter{}
+	logger := NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+		ExitCode: 3,
+		ExitFunc: func(c int) {
+			code = c
+			calls = append(calls, "exit")
+		},
+	}).AddWriter(w1)
+	other := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w2)
+	defer other.Close()
+
+	logger.OnExit(func() {
+		calls = append(calls, "hook")
+		logger.Info("shutting down")
+	})
+	other.Info("pending")
+	logger.Prefix("[C] ").Fatal("fatal")
+
+	if code != 3 || strings.Join(calls, ",") != "hook,exit" {
+		t.Fatalf("unexpected exit: %d %v", code, calls)
+	}
+	if lines := w1.Lines(); len(lines) != 2 || !w1.closed || !strings.Contains(lines[0], `msg="[C] fatal"`) {
+		t.Fatalf("expect logger closed after fatal log, got %v", lines)
+	}
+	if lines := w2.Lines(); len(lines) != 1 || w2.flushed == 0 {
+		t.Fatal("expect other loggers flushed before exit")
+	}
+}
+
+func TestFatalInExitHook(t *testing.T) {
+	var exits int
+	w := &testWriter{}
+	logger := NewWithOptions(NewTextEncoder("", ""), LoggerOptions{
+		ExitFunc: func(int) { exits++ },
+	}).AddWriter(w)
+	logger.OnExit(func() {
+		logger.Fatal("nested")
+	})
+	logger.Fatal("fatal")
+
+	if exits != 1 {
+		t.Fatalf("expect exit once, got %d", exits)
+	}
+	if lines := w.Lines(); len(lines) != 2 || !strings.Contains(lines[1], `msg="nested"`) {
+		t.Fatalf("expect nested fatal logged, got %v", lines)
+	}
+}
+
+func TestPanicMode(t *testing.T) {
+	recovered := func(fn func()) (r interface{}) {
+		defer func() {
+			r = recover()
+		}()
+		fn()
+		return nil
+	}
+
+	w := &testWriter{}
+	logger := New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(w)
+	if r := recovered(func() { logger.Panicf("a %d", 1) }); r != "a 1" {
+		t.Errorf("expect string panic value, got %v", r)
+	}
+	logger.Close()
+
+	cause := errors.New("cause")
+	logger = NewWithOptions(NewTextEncoder("", ""), LoggerOptions{PanicMode: PanicTyped}).AddWriter(w)
+	r := recovered(func() { logger.WithFields("k", 1, "err", cause).Panic("b") })
+	e, ok := r.(*PanicError)
+	if !ok || e.Message != "b" || len(e.Fields) != 2 || e.Fields[0].Key != "k" || !errors.Is(e, cause) {
+		t.Errorf("unexpected typed panic value: %#v", r)
+	}
+	logger.Close()
+
+	logger = NewWithOptions(NewTextEncoder("", ""), LoggerOptions{PanicMode: PanicNone}).AddWriter(w)
+	if r := recovered(func() { logger.Panic("c") }); r != nil {
+		t.Errorf("expect no panic, got %v", r)
+	}
+	logger.Close()
+	if lines := w.Lines(); len(lines) != 3 || !strings.HasPrefix(lines[2], `level="PANIC"`) {
+		t.Fatalf("expect all panic logs written, got %v", lines)
+	}
+}
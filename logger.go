@@ -1,7 +1,11 @@
 package golog
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -10,20 +14,43 @@ import (
 type (
 	Logger interface {
 		AddWriter(Writer) Logger
+		// WriterErrors returns the count of errors returned by the writer
+		WriterErrors(Writer) uint64
 		Level() Level
+		// SetLevel changes the level of the logger and all loggers derived from it or it's parent
+		SetLevel(Level)
+		// OnExit registers a hook to be called before exiting process by Fatal logs, hooks
+		// are shared by the logger family
+		OnExit(func())
+		// SetLevelRules overrides level of logs by package of the caller with comma-separated
+		// rules such as "github.com/org/db/*=DEBUG, *=WARN", empty rules clears all. The level
+		// of logger is used for packages matches no rule, the "*" rule is same as SetLevel.
+		SetLevelRules(string) error
 		Flush()
 		Close()
 		Prefix(string) Logger
+		// With returns a child logger which add fields to every log, args is same as the
+		// arguments of WithFields
+		With(...interface{}) Logger
 
 		WithField(key string, val interface{}) *Log
 		WithFields(...interface{}) *Log
-
+		// WithTypedFields is same as WithFields but avoids boxing fields created by typed
+		// constructors such as String and Int64 into interfaces. Fields passed inline escape
+		// through the interface and cost one allocation per call, logging with a reused
+		// fields slice doesn't allocate
+		WithTypedFields(...Field) *Log
+		// WithContext returns a Log contains fields carried by the context
+		WithContext(context.Context) *Log
+
+		Trace(...interface{})
 		Debug(...interface{})
 		Info(...interface{})
 		Warn(...interface{})
 		Error(...interface{})
 		Panic(...interface{})
 		Fatal(...interface{}) // exit process
+		Tracef(string, ...interface{})
 		Debugf(string, ...interface{})
 		Infof(string, ...interface{})
 		Warnf(string, ...interface{})
@@ -32,7 +59,23 @@ type (
 		Fatalf(string, ...interface{})
 		Depth(Level, int, ...interface{})
 		Depthf(Level, int, string, ...interface{})
+		TraceContext(context.Context, ...interface{})
+		DebugContext(context.Context, ...interface{})
+		InfoContext(context.Context, ...interface{})
+		WarnContext(context.Context, ...interface{})
+		ErrorContext(context.Context, ...interface{})
+		PanicContext(context.Context, ...interface{})
+		FatalContext(context.Context, ...interface{})
+		DepthContext(context.Context, Level, int, ...interface{})
 		Write(*Log)
+
+		// Recover recovers panic and logs it by LogPanic, then panic again if repanic is true.
+		// It must be called directly by defer, like defer logger.Recover(LevelError, false).
+		Recover(level Level, repanic bool)
+		// LogPanic logs a recovered panic value with stack trace at level, the position of log
+		// is where the panic occurred, writers are flushed before return. It never panics or
+		// exits process even if level is LevelPanic or LevelFatal.
+		LogPanic(level Level, value interface{})
 	}
 
 	Writer interface {
@@ -41,148 +84,649 @@ type (
 		Flush()
 	}
 
+	// OverflowPolicy decide what to do when the queue of logger is full.
+	OverflowPolicy uint8
+
+	// PanicMode decide how LevelPanic logs panic.
+	PanicMode uint8
+
+	// ErrorHandler handles errors returned by writers, it's called on the background
+	// goroutine of logger without holding any lock. It may add writers, but must not
+	// call Flush of the same logger or block on it, e.g. logging with OverflowBlock
+	// when the queue is full, the background goroutine waits until it returns.
+	ErrorHandler func(Writer, error)
+
+	LoggerOptions struct {
+		// minimum log level, default LevelDebug
+		Level Level
+		// writers flush interval seconds, default 30
+		FlushSeconds int
+		// capacity of the queue between callers and writers, default 100
+		Backlog int
+		// what to do when the queue is full
+		Overflow OverflowPolicy
+		// logs below this level are dropped when the queue is full if Overflow is OverflowDropBelow,
+		// default LevelDebug
+		OverflowLevel Level
+		// interval seconds of the warning of dropped logs, default 60, <0 to disable
+		DropReportSeconds int
+		// handler of writer errors, default StderrErrorHandler(time.Minute)
+		ErrorHandler ErrorHandler
+		// minimum level of logs to capture stack trace, default LevelError, LevelDebug is
+		// treated as unset, use LevelTrace to capture stack trace for all logs
+		StackLevel Level
+		// max frames of stack traces, 0 to disable
+		StackFrames int
+		// process exit code of Fatal logs, default -1
+		ExitCode int
+		// function to exit process for Fatal logs, default os.Exit
+		ExitFunc func(code int)
+		// how LevelPanic logs panic, default PanicString
+		PanicMode PanicMode
+	}
+
+	// logger is a view of loggerCore with it's own prefix and fields, loggers derived
+	// by Prefix and With share the core of their parent.
 	logger struct {
-		wg      sync.WaitGroup
-		encoder Encoder
-		level   Level
-		writers []Writer
-		prefix  string
+		*loggerCore
+		prefix string
+		fields []Field
+	}
+
+	// loggerCore holds the states shared by a logger family.
+	loggerCore struct {
+		wg           sync.WaitGroup
+		encoder      Encoder
+		level        LevelVar
+		levelRules   atomic.Value // *levelRules
+		errorHandler ErrorHandler
+		stackLevel   Level
+		stackFrames  int
+
+		panicMode PanicMode
+		exitCode  int
+		exitFunc  func(int)
+		hooksMu   sync.Mutex
+		exitHooks []func()
+		exitFlag  int32
+
+		writersMu sync.RWMutex
+		writers   []*loggerWriter
+
+		overflow           OverflowPolicy
+		overflowLevel      Level
+		dropped            [levelCount]uint64
+		dropReportInterval time.Duration
 
 		flushInterval time.Duration
-		flush         chan struct{}
+		flush         chan chan struct{}
+		queue         chan logEntry
+		quit          chan struct{}
+		done          chan struct{}
 
 		closeFlag int32
 	}
+
+	loggerWriter struct {
+		Writer
+		errors uint64
+	}
+
+	logEntry struct {
+		level Level
+		buf   *bytes.Buffer
+	}
+)
+
+const (
+	// OverflowBlock block the caller until the queue has space.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drop the log being written.
+	OverflowDropNewest
+	// OverflowDropOldest drop the oldest log in the queue to make room.
+	OverflowDropOldest
+	// OverflowDropBelow drop the log being written if it's level is below
+	// LoggerOptions.OverflowLevel, otherwise block the caller.
+	OverflowDropBelow
 )
 
+const (
+	// PanicString panic with the formatted message.
+	PanicString PanicMode = iota
+	// PanicTyped panic with a *PanicError carries the message, fields and error of the log.
+	PanicTyped
+	// PanicNone don't panic, LevelPanic logs are only logged.
+	PanicNone
+)
+
+// New create a logger. Logs are encoded on the caller's goroutine and written to
+// writers by a background goroutine, backlog is the capacity of the queue between them.
 func New(level Level, flushSeconds, backlog int, encoder Encoder) Logger {
-	if flushSeconds == 0 {
-		flushSeconds = 30
+	return NewWithOptions(encoder, LoggerOptions{
+		Level:        level,
+		FlushSeconds: flushSeconds,
+		Backlog:      backlog,
+	})
+}
+
+func NewWithOptions(encoder Encoder, opts LoggerOptions) Logger {
+	if opts.FlushSeconds == 0 {
+		opts.FlushSeconds = 30
+	}
+	if opts.Backlog == 0 {
+		opts.Backlog = 100
+	}
+	if opts.DropReportSeconds == 0 {
+		opts.DropReportSeconds = 60
 	}
-	if backlog == 0 {
-		backlog = 100
+	if opts.ErrorHandler == nil {
+		opts.ErrorHandler = StderrErrorHandler(time.Minute)
+	}
+	if opts.StackFrames > 0 && opts.StackLevel == LevelDebug {
+		opts.StackLevel = LevelError
+	}
+	if opts.ExitCode == 0 {
+		opts.ExitCode = -1
+	}
+	if opts.ExitFunc == nil {
+		opts.ExitFunc = os.Exit
 	}
 
-	l := &logger{
-		level:   level,
-		encoder: encoder,
+	c := &loggerCore{
+		encoder:       encoder,
+		overflow:      opts.Overflow,
+		overflowLevel: opts.OverflowLevel,
+		errorHandler:  opts.ErrorHandler,
+		stackLevel:    opts.StackLevel,
+		stackFrames:   opts.StackFrames,
+		panicMode:     opts.PanicMode,
+		exitCode:      opts.ExitCode,
+		exitFunc:      opts.ExitFunc,
+		flushInterval: time.Duration(opts.FlushSeconds) * time.Second,
+		flush:         make(chan chan struct{}),
+		queue:         make(chan logEntry, opts.Backlog),
+		quit:          make(chan struct{}),
+		done:          make(chan struct{}),
 	}
-	if level <= levelMax {
-		l.flush = make(chan struct{}, 1)
-		l.flushInterval = time.Duration(flushSeconds) * time.Second
+	c.level.Set(opts.Level)
+	if opts.DropReportSeconds > 0 {
+		c.dropReportInterval = time.Duration(opts.DropReportSeconds) * time.Second
 	}
-	l.start()
-	return l
+	c.start()
+	liveLoggers.add(c)
+	return &logger{loggerCore: c}
+}
+
+// liveLoggers records cores not closed yet, they are all flushed before exiting by Fatal logs.
+var liveLoggers = loggerSet{
+	cores: make(map[*loggerCore]struct{}),
 }
 
-func (l *logger) isClosed() bool {
-	return atomic.LoadInt32(&l.closeFlag) == 1
+type loggerSet struct {
+	mu    sync.Mutex
+	cores map[*loggerCore]struct{}
 }
 
-func (l *logger) markClosed() bool {
-	return atomic.CompareAndSwapInt32(&l.closeFlag, 0, 1)
+func (s *loggerSet) add(c *loggerCore) {
+	s.mu.Lock()
+	s.cores[c] = struct{}{}
+	s.mu.Unlock()
 }
 
+func (s *loggerSet) remove(c *loggerCore) {
+	s.mu.Lock()
+	delete(s.cores, c)
+	s.mu.Unlock()
+}
+
+func (s *loggerSet) list() []*loggerCore {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cores := make([]*loggerCore, 0, len(s.cores))
+	for c := range s.cores {
+		cores = append(cores, c)
+	}
+	return cores
+}
+
+func (c *loggerCore) isClosed() bool {
+	return atomic.LoadInt32(&c.closeFlag) == 1
+}
+
+func (c *loggerCore) markClosed() bool {
+	return atomic.CompareAndSwapInt32(&c.closeFlag, 0, 1)
+}
+
+// StderrErrorHandler create an ErrorHandler which reports errors to stderr at most once
+// per interval, count of errors suppressed in the interval is reported together.
+func StderrErrorHandler(interval time.Duration) ErrorHandler {
+	var (
+		mu         sync.Mutex
+		last       time.Time
+		suppressed int
+	)
+	return func(w Writer, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := time.Now()
+		if !last.IsZero() && now.Sub(last) < interval {
+			suppressed++
+			return
+		}
+		last = now
+		if suppressed > 0 {
+			fmt.Fprintf(os.Stderr, "golog: write to %T failed: %s (%d errors suppressed)\n", w, err, suppressed)
+		} else {
+			fmt.Fprintf(os.Stderr, "golog: write to %T failed: %s\n", w, err)
+		}
+		suppressed = 0
+	}
+}
+
+// AddWriter add writer to the logger family.
 func (l *logger) AddWriter(w Writer) Logger {
-	l.writers = append(l.writers, w)
+	l.writersMu.Lock()
+	l.writers = append(l.writers, &loggerWriter{Writer: w})
+	l.writersMu.Unlock()
 	return l
 }
 
+func (c *loggerCore) WriterErrors(w Writer) uint64 {
+	c.writersMu.RLock()
+	defer c.writersMu.RUnlock()
+
+	for _, lw := range c.writers {
+		if lw.Writer == w {
+			return atomic.LoadUint64(&lw.errors)
+		}
+	}
+	return 0
+}
+
 func (l *logger) Prefix(p string) Logger {
 	if p == l.prefix {
 		return l
 	}
 
-	nl := *l
-	nl.prefix = p
-	return &nl
+	return &logger{
+		loggerCore: l.loggerCore,
+		prefix:     p,
+		fields:     l.fields,
+	}
 }
 
-func (l *logger) Level() Level {
-	return l.level
+func (l *logger) With(args ...interface{}) Logger {
+	fields := make([]Field, len(l.fields), len(l.fields)+len(args)/2)
+	copy(fields, l.fields)
+
+	return &logger{
+		loggerCore: l.loggerCore,
+		prefix:     l.prefix,
+		fields:     appendFields(fields, args...),
+	}
 }
 
-func (l *logger) start() {
-	go func(l *logger) {
-		ticker := time.Tick(l.flushInterval)
+func (c *loggerCore) Level() Level {
+	return c.level.Level()
+}
+
+// SetLevel changes the level of the logger family.
+func (c *loggerCore) SetLevel(level Level) {
+	c.level.Set(level)
+}
+
+func (c *loggerCore) SetLevelRules(rules string) error {
+	r, err := parseLevelRules(rules)
+	if err != nil {
+		return err
+	}
+	if r != nil && r.hasDef {
+		c.SetLevel(r.def)
+	}
+	if r != nil && len(r.rules) == 0 {
+		r = nil
+	}
+	c.levelRules.Store(r)
+	return nil
+}
+
+func (c *loggerCore) rules() *levelRules {
+	r, _ := c.levelRules.Load().(*levelRules)
+	return r
+}
+
+// minLevel returns the minimum level may be logged by any caller.
+func (c *loggerCore) minLevel() Level {
+	if r := c.rules(); r != nil {
+		return r.minLevel(c.Level())
+	}
+	return c.Level()
+}
+
+// levelOf returns the level of the caller at pc.
+func (c *loggerCore) levelOf(pc uintptr) Level {
+	if r := c.rules(); r != nil {
+		return r.level(pc, c.Level())
+	}
+	return c.Level()
+}
+
+// start runs the background goroutine which drains the queue into writers and
+// flushes them periodically or on demand.
+func (c *loggerCore) start() {
+	go func() {
+		defer close(c.done)
+
+		// never flush periodically if the interval is negative
+		var flushTick <-chan time.Time
+		if c.flushInterval > 0 {
+			t := time.NewTicker(c.flushInterval)
+			defer t.Stop()
+			flushTick = t.C
+		}
+		var dropReport <-chan time.Time
+		if c.dropReportInterval > 0 {
+			t := time.NewTicker(c.dropReportInterval)
+			defer t.Stop()
+			dropReport = t.C
+		}
 		for {
 			select {
-			case <-ticker:
-			case _, ok := <-l.flush:
-				if !ok {
-					return
-				}
+			case e := <-c.queue:
+				c.writeEntry(e)
+			case <-flushTick:
+				c.flushWriters()
+			case <-dropReport:
+				c.reportDropped()
+			case done := <-c.flush:
+				c.drainQueue()
+				c.flushWriters()
+				close(done)
+			case <-c.quit:
+				c.drainQueue()
+				c.reportDropped()
+				return
 			}
+		}
+	}()
+}
+
+type writerError struct {
+	writer Writer
+	err    error
+}
+
+// writeEntry writes the entry to all writers, errors are handled after the writers
+// lock is released so the error handler is free to add writers or log.
+func (c *loggerCore) writeEntry(e logEntry) {
+	var (
+		errs   [4]writerError
+		failed = errs[:0]
+	)
+	c.writersMu.RLock()
+	bytes := e.buf.Bytes()
+	for _, writer := range c.writers {
+		if err := writer.Write(e.level, bytes); err != nil {
+			atomic.AddUint64(&writer.errors, 1)
+			failed = append(failed, writerError{writer: writer.Writer, err: err})
+		}
+	}
+	c.writersMu.RUnlock()
+	freeBuffer(e.buf)
 
-			for _, writer := range l.writers {
-				writer.Flush()
-			}
+	for _, f := range failed {
+		c.errorHandler(f.writer, f.err)
+	}
+}
+
+func (c *loggerCore) drainQueue() {
+	for {
+		select {
+		case e := <-c.queue:
+			c.writeEntry(e)
+		default:
+			return
 		}
-	}(l)
+	}
 }
 
-func (l *logger) doWrite(log *Log) {
-	l.wg.Add(1)
-	defer l.wg.Done()
+func (c *loggerCore) flushWriters() {
+	c.writersMu.RLock()
+	defer c.writersMu.RUnlock()
 
-	level := log.Level
-	buf := allocBuffer()
-	l.encoder.Encode(buf, log)
+	for _, writer := range c.writers {
+		writer.Flush()
+	}
+}
+
+func (c *loggerCore) encode(log *Log) logEntry {
+	e := logEntry{
+		level: log.Level,
+		buf:   allocBuffer(),
+	}
+	c.encoder.Encode(e.buf, log)
 	freeLog(log)
+	return e
+}
+
+// doWrite encodes log on the caller's goroutine and queues the result for the
+// background goroutine, what to do if the queue is full depends on the overflow policy.
+func (c *loggerCore) doWrite(log *Log) {
+	c.wg.Add(1)
+	defer c.wg.Done()
+
+	e := c.encode(log)
+	switch {
+	case c.overflow == OverflowDropNewest,
+		c.overflow == OverflowDropBelow && e.level < c.overflowLevel:
+		select {
+		case c.queue <- e:
+		default:
+			c.drop(e)
+		}
+	case c.overflow == OverflowDropOldest:
+		for {
+			select {
+			case c.queue <- e:
+				return
+			default:
+			}
+			select {
+			case old := <-c.queue:
+				c.drop(old)
+			default:
+			}
+		}
+	default:
+		select {
+		case c.queue <- e:
+		case <-c.quit:
+			freeBuffer(e.buf)
+		}
+	}
+}
+
+func (c *loggerCore) drop(e logEntry) {
+	level := e.level
+	if level > levelMax {
+		level = levelMax
+	} else if level < levelMin {
+		level = levelMin
+	}
+	atomic.AddUint64(&c.dropped[level-levelMin], 1)
+	freeBuffer(e.buf)
+}
 
-	bytes := buf.Bytes()
-	for _, writer := range l.writers {
-		if l.isClosed() {
-			break
+// reportDropped writes a warning of dropped logs since last report directly to writers,
+// regardless of the log level.
+func (c *loggerCore) reportDropped() {
+	log := allocLog()
+	log.Time = time.Now()
+	var total uint64
+	for level := levelMin; level <= levelMax; level++ {
+		if n := atomic.SwapUint64(&c.dropped[level-levelMin], 0); n > 0 {
+			total += n
+			log.appendField(level.String(), n)
 		}
-		writer.Write(level, bytes)
 	}
-	freeBuffer(buf)
+	if total == 0 {
+		freeLog(log)
+		return
+	}
+
+	log.Level = LevelWarn
+	log.Format = "%d entries dropped"
+	log.Args = append(log.Args[:0], total)
+	c.writeEntry(c.encode(log))
 }
 
-func (l *logger) Write(log *Log) {
-	level := log.Level
-	if level < l.level || l.isClosed() {
+// accept frees log and returns false if it's below the level or the logger is closed.
+func (l *logger) accept(log *Log) bool {
+	if log.Level < l.levelOf(log.pc) || l.isClosed() {
 		freeLog(log)
+		return false
+	}
+	return true
+}
+
+func (l *logger) Write(log *Log) {
+	if !l.accept(log) {
 		return
 	}
+	level := log.Level
+	if l.stackFrames > 0 && level >= l.stackLevel && len(log.Stack) == 0 {
+		log.Stack = captureStack(log.Stack, l.stackFrames, log.pc)
+	}
+	var panicValue interface{}
+	if level == LevelPanic {
+		switch l.panicMode {
+		case PanicString:
+			panicValue = log.panicInfo()
+		case PanicTyped:
+			panicValue = log.panicError()
+		}
+	}
 	l.doWrite(log)
 
-	if level == LevelPanic {
-		panic(log.panicInfo())
+	if panicValue != nil {
+		l.Flush()
+		panic(panicValue)
 	}
 	if level == LevelFatal {
-		l.Close()
-		os.Exit(-1)
+		l.exit()
 	}
 }
 
-func (l *logger) Flush() {
-	f := l.flush
-	if !l.isClosed() && f != nil {
-		select {
-		case f <- struct{}{}:
-		default:
+func (c *loggerCore) OnExit(hook func()) {
+	c.hooksMu.Lock()
+	c.exitHooks = append(c.exitHooks, hook)
+	c.hooksMu.Unlock()
+}
+
+// exit runs exit hooks, flushes all other loggers and closes this one, then exits process.
+// It runs only once, Fatal logs from exit hooks are logged without exiting again.
+func (c *loggerCore) exit() {
+	if !atomic.CompareAndSwapInt32(&c.exitFlag, 0, 1) {
+		return
+	}
+	c.hooksMu.Lock()
+	hooks := c.exitHooks
+	c.hooksMu.Unlock()
+	for _, hook := range hooks {
+		hook()
+	}
+
+	for _, other := range liveLoggers.list() {
+		if other != c {
+			other.Flush()
 		}
 	}
+	c.Close()
+	c.exitFunc(c.exitCode)
+}
+
+func (l *logger) Recover(level Level, repanic bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	l.LogPanic(level, r)
+	if repanic {
+		panic(r)
+	}
+}
+
+// defaultPanicFrames is the max frames of stack trace of recovered panics if
+// LoggerOptions.StackFrames is 0.
+const defaultPanicFrames = 32
+
+func (l *logger) LogPanic(level Level, value interface{}) {
+	frames := l.stackFrames
+	if frames <= 0 {
+		frames = defaultPanicFrames
+	}
+	log := l.newLog(l.prefix)
+	log.Stack = captureStack(log.Stack, frames+4, 0)
+	var start int
+	for start < len(log.Stack)-1 && strings.HasPrefix(log.Stack[start].Function, "runtime.") {
+		start++
+	}
+	log.Stack = append(log.Stack[:0], log.Stack[start:]...)
+	if len(log.Stack) > frames {
+		log.Stack = log.Stack[:frames]
+	}
+	if len(log.Stack) > 0 {
+		log.Position = formatPos(log.Stack[0].File, log.Stack[0].Line)
+	}
+	log.Level = level
+	log.Args = append(log.Args[:0], "recovered from panic")
+	log.appendField("panic", value)
+
+	// panic or exit of LevelPanic and LevelFatal logs are skipped, it's up to the caller
+	if l.accept(log) {
+		l.doWrite(log)
+		l.Flush()
+	}
 }
 
-func (l *logger) Close() {
-	if !l.markClosed() {
+// Flush writes all queued logs and flushes writers, it returns after they were done.
+func (c *loggerCore) Flush() {
+	if c.isClosed() {
 		return
 	}
-	l.wg.Wait()
 
-	for _, w := range l.writers {
+	done := make(chan struct{})
+	select {
+	case c.flush <- done:
+		<-done
+	case <-c.quit:
+	}
+}
+
+// Close writes all queued logs, then closes writers, the whole logger family is closed.
+func (c *loggerCore) Close() {
+	if !c.markClosed() {
+		return
+	}
+	c.wg.Wait()
+	close(c.quit)
+	<-c.done
+	liveLoggers.remove(c)
+
+	c.writersMu.RLock()
+	defer c.writersMu.RUnlock()
+	for _, w := range c.writers {
 		w.Close()
 	}
-	close(l.flush)
 }
 
 func (l *logger) newLog(prefix string) *Log {
 	log := allocLog()
 	log.Time = time.Now()
 	log.Prefix = prefix
+	log.Fields = append(log.Fields, l.fields...)
 	log.logger = l
 	return log
 }
@@ -195,16 +739,32 @@ func (l *logger) WithFields(args ...interface{}) *Log {
 	return l.newLog(l.prefix).appendFields(args...)
 }
 
+func (l *logger) WithTypedFields(fields ...Field) *Log {
+	log := l.newLog(l.prefix)
+	log.Fields = append(log.Fields, fields...)
+	return log
+}
+
+func (l *logger) WithContext(ctx context.Context) *Log {
+	log := l.newLog(l.prefix)
+	log.Fields = append(log.Fields, ContextFields(ctx)...)
+	return log
+}
+
 func (l *logger) Depth(level Level, depth int, args ...interface{}) {
 	l.Depthf(level, depth+1, "", args...)
 }
 
 func (l *logger) Depthf(level Level, depth int, format string, args ...interface{}) {
-	if level >= l.level {
+	if level >= l.minLevel() {
 		l.newLog(l.prefix).Depthf(level, depth+1, format, args...)
 	}
 }
 
+func (l *logger) Tracef(format string, args ...interface{}) {
+	l.Depthf(LevelTrace, 1, format, args...)
+}
+
 func (l *logger) Debugf(format string, args ...interface{}) {
 	l.Depthf(LevelDebug, 1, format, args...)
 }
@@ -229,6 +789,10 @@ func (l *logger) Fatalf(format string, args ...interface{}) {
 	l.Depthf(LevelFatal, 1, format, args...)
 }
 
+func (l *logger) Trace(args ...interface{}) {
+	l.Depth(LevelTrace, 1, args...)
+}
+
 func (l *logger) Debug(args ...interface{}) {
 	l.Depth(LevelDebug, 1, args...)
 }
@@ -253,6 +817,40 @@ func (l *logger) Fatal(args ...interface{}) {
 	l.Depth(LevelFatal, 1, args...)
 }
 
+func (l *logger) DepthContext(ctx context.Context, level Level, depth int, args ...interface{}) {
+	if level >= l.minLevel() {
+		l.WithContext(ctx).Depth(level, depth+1, args...)
+	}
+}
+
+func (l *logger) TraceContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelTrace, 1, args...)
+}
+
+func (l *logger) DebugContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelDebug, 1, args...)
+}
+
+func (l *logger) InfoContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelInfo, 1, args...)
+}
+
+func (l *logger) WarnContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelWarn, 1, args...)
+}
+
+func (l *logger) ErrorContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelError, 1, args...)
+}
+
+func (l *logger) PanicContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelPanic, 1, args...)
+}
+
+func (l *logger) FatalContext(ctx context.Context, args ...interface{}) {
+	l.DepthContext(ctx, LevelFatal, 1, args...)
+}
+
 var DefaultLogger = New(LevelDebug, 0, 0, NewTextEncoder("", "")).AddWriter(Console())
 
 func Depth(level Level, depth int, args ...interface{}) {
@@ -263,6 +861,10 @@ func Depthf(level Level, depth int, format string, args ...interface{}) {
 	DefaultLogger.Depthf(level, depth+1, format, args...)
 }
 
+func Tracef(format string, args ...interface{}) {
+	DefaultLogger.Depthf(LevelTrace, 1, format, args...)
+}
+
 func Debugf(format string, args ...interface{}) {
 	DefaultLogger.Depthf(LevelDebug, 1, format, args...)
 }
@@ -287,6 +889,10 @@ func Fatalf(format string, args ...interface{}) {
 	DefaultLogger.Depthf(LevelFatal, 1, format, args...)
 }
 
+func Trace(args ...interface{}) {
+	DefaultLogger.Depth(LevelTrace, 1, args...)
+}
+
 func Debug(args ...interface{}) {
 	DefaultLogger.Depth(LevelDebug, 1, args...)
 }
@@ -311,6 +917,30 @@ func Fatal(args ...interface{}) {
 	DefaultLogger.Depth(LevelFatal, 1, args...)
 }
 
+// Recover is same as Logger.Recover of DefaultLogger, it must be called directly by defer.
+func Recover(level Level, repanic bool) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	DefaultLogger.LogPanic(level, r)
+	if repanic {
+		panic(r)
+	}
+}
+
+func OnExit(hook func()) {
+	DefaultLogger.OnExit(hook)
+}
+
+func SetLevel(level Level) {
+	DefaultLogger.SetLevel(level)
+}
+
+func With(args ...interface{}) Logger {
+	return DefaultLogger.With(args...)
+}
+
 func WithField(key string, val interface{}) *Log {
 	return DefaultLogger.WithField(key, val)
 }
@@ -318,3 +948,7 @@ func WithField(key string, val interface{}) *Log {
 func WithFields(args ...interface{}) *Log {
 	return DefaultLogger.WithFields(args...)
 }
+
+func WithTypedFields(fields ...Field) *Log {
+	return DefaultLogger.WithTypedFields(fields...)
+}
@@ -0,0 +1,210 @@
+package golog
+
+import (
+	"bytes"
+	"time"
+)
+
+type (
+	// ObjectMarshaler is implemented by types to encode themselves as objects without
+	// reflection, it works same for JSONEncoder and TextEncoder.
+	ObjectMarshaler interface {
+		MarshalLogObject(ObjectEncoder) error
+	}
+
+	// ArrayMarshaler is implemented by types to encode themselves as arrays without reflection.
+	ArrayMarshaler interface {
+		MarshalLogArray(ArrayEncoder) error
+	}
+
+	ObjectEncoder interface {
+		AddString(key, val string)
+		AddInt(key string, val int)
+		AddInt64(key string, val int64)
+		AddUint64(key string, val uint64)
+		AddFloat64(key string, val float64)
+		AddBool(key string, val bool)
+		AddDuration(key string, val time.Duration)
+		AddTime(key string, val time.Time)
+		AddObject(key string, val ObjectMarshaler) error
+		AddArray(key string, val ArrayMarshaler) error
+		// AddAny encode val same as field values
+		AddAny(key string, val interface{})
+	}
+
+	ArrayEncoder interface {
+		AppendString(string)
+		AppendInt(int)
+		AppendInt64(int64)
+		AppendUint64(uint64)
+		AppendFloat64(float64)
+		AppendBool(bool)
+		AppendDuration(time.Duration)
+		AppendTime(time.Time)
+		AppendObject(ObjectMarshaler) error
+		AppendArray(ArrayMarshaler) error
+		AppendAny(interface{})
+	}
+)
+
+// EncodeObject encode the marshaler as an object, if it returns an error, the error is
+// encoded instead.
+func (t *TypeEncoder) EncodeObject(buf *bytes.Buffer, m ObjectMarshaler) {
+	n := buf.Len()
+	if err := t.marshalObject(buf, m); err != nil {
+		buf.Truncate(n)
+		t.EncodeError(buf, err)
+	}
+}
+
+// EncodeArray is same as EncodeObject, but for arrays.
+func (t *TypeEncoder) EncodeArray(buf *bytes.Buffer, m ArrayMarshaler) {
+	n := buf.Len()
+	if err := t.marshalArray(buf, m); err != nil {
+		buf.Truncate(n)
+		t.EncodeError(buf, err)
+	}
+}
+
+func (t *TypeEncoder) marshalObject(buf *bytes.Buffer, m ObjectMarshaler) error {
+	buf.WriteByte('{')
+	err := m.MarshalLogObject(&marshalEncoder{enc: t, buf: buf})
+	buf.WriteByte('}')
+	return err
+}
+
+func (t *TypeEncoder) marshalArray(buf *bytes.Buffer, m ArrayMarshaler) error {
+	buf.WriteByte('[')
+	err := m.MarshalLogArray(&marshalEncoder{enc: t, buf: buf})
+	buf.WriteByte(']')
+	return err
+}
+
+// marshalEncoder implements ObjectEncoder and ArrayEncoder by the TypeEncoder.
+type marshalEncoder struct {
+	enc *TypeEncoder
+	buf *bytes.Buffer
+	n   int
+}
+
+func (m *marshalEncoder) elem() {
+	if m.n > 0 {
+		m.enc.writeElemSeparator(m.buf)
+	}
+	m.n++
+}
+
+func (m *marshalEncoder) key(key string) {
+	m.elem()
+	m.enc.encodeKey(m.buf, key)
+}
+
+func (m *marshalEncoder) AddString(key, val string) {
+	m.key(key)
+	m.enc.EncodeString(m.buf, val)
+}
+
+func (m *marshalEncoder) AddInt(key string, val int) {
+	m.key(key)
+	m.enc.EncodeInt(m.buf, int64(val))
+}
+
+func (m *marshalEncoder) AddInt64(key string, val int64) {
+	m.key(key)
+	m.enc.EncodeInt(m.buf, val)
+}
+
+func (m *marshalEncoder) AddUint64(key string, val uint64) {
+	m.key(key)
+	m.enc.EncodeUint(m.buf, val)
+}
+
+func (m *marshalEncoder) AddFloat64(key string, val float64) {
+	m.key(key)
+	m.enc.EncodeFloat(m.buf, val)
+}
+
+func (m *marshalEncoder) AddBool(key string, val bool) {
+	m.key(key)
+	m.enc.EncodeBool(m.buf, val)
+}
+
+func (m *marshalEncoder) AddDuration(key string, val time.Duration) {
+	m.key(key)
+	m.enc.EncodeDuration(m.buf, val)
+}
+
+func (m *marshalEncoder) AddTime(key string, val time.Time) {
+	m.key(key)
+	m.enc.EncodeTime(m.buf, val)
+}
+
+func (m *marshalEncoder) AddObject(key string, val ObjectMarshaler) error {
+	m.key(key)
+	return m.enc.marshalObject(m.buf, val)
+}
+
+func (m *marshalEncoder) AddArray(key string, val ArrayMarshaler) error {
+	m.key(key)
+	return m.enc.marshalArray(m.buf, val)
+}
+
+func (m *marshalEncoder) AddAny(key string, val interface{}) {
+	m.key(key)
+	m.enc.EncodeVal(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendString(val string) {
+	m.elem()
+	m.enc.EncodeString(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendInt(val int) {
+	m.elem()
+	m.enc.EncodeInt(m.buf, int64(val))
+}
+
+func (m *marshalEncoder) AppendInt64(val int64) {
+	m.elem()
+	m.enc.EncodeInt(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendUint64(val uint64) {
+	m.elem()
+	m.enc.EncodeUint(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendFloat64(val float64) {
+	m.elem()
+	m.enc.EncodeFloat(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendBool(val bool) {
+	m.elem()
+	m.enc.EncodeBool(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendDuration(val time.Duration) {
+	m.elem()
+	m.enc.EncodeDuration(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendTime(val time.Time) {
+	m.elem()
+	m.enc.EncodeTime(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendObject(val ObjectMarshaler) error {
+	m.elem()
+	return m.enc.marshalObject(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendArray(val ArrayMarshaler) error {
+	m.elem()
+	return m.enc.marshalArray(m.buf, val)
+}
+
+func (m *marshalEncoder) AppendAny(val interface{}) {
+	m.elem()
+	m.enc.EncodeVal(m.buf, val)
+}
@@ -33,11 +33,16 @@ func allocLog() *Log {
 func freeLog(log *Log) {
 	log.Level = 0
 	log.Time.Truncate(0)
+	log.pc = 0
 	log.Position = ""
 	log.Prefix = ""
 	log.Format = ""
-	log.Args = nil
+	for i := range log.Args {
+		log.Args[i] = nil
+	}
+	log.Args = log.Args[:0]
 	log.Fields = log.Fields[:0]
+	log.Stack = log.Stack[:0]
 	log.logger = nil
 	logPool.Put(log)
 }
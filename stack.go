@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Frame is a frame of stack trace.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+func (f Frame) String() string {
+	return f.Function + " " + f.File + ":" + strconv.Itoa(f.Line)
+}
+
+// gologPackage is the package path of golog, it's frames are trimmed from stack traces.
+var gologPackage = func() string {
+	pc, _ := caller(0)
+	return callerPackage(pc)
+}()
+
+func isGologFrame(frame *runtime.Frame) bool {
+	return strings.HasPrefix(frame.Function, gologPackage+".")
+}
+
+// captureStack append at most n frames of current goroutine's stack to frames. Frames
+// are started from the function of pc, the caller logging, or from the first non-golog
+// function if pc is 0.
+func captureStack(frames []Frame, n int, pc uintptr) []Frame {
+	const gologFrames = 16
+
+	var caller string
+	if fn := runtime.FuncForPC(pc); pc != 0 && fn != nil {
+		caller = fn.Name()
+	}
+	pcs := make([]uintptr, n+gologFrames)
+	callers := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for count, started := 0, false; count < n; {
+		frame, more := callers.Next()
+		if !started {
+			if caller != "" {
+				started = frame.Function == caller
+			} else {
+				started = !isGologFrame(&frame)
+			}
+		}
+		if started && frame.Function != "" {
+			frames = append(frames, Frame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+			})
+			count++
+		}
+		if !more {
+			break
+		}
+	}
+	return frames
+}
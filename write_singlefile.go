@@ -2,17 +2,29 @@ package golog
 
 import (
 	"bufio"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 )
 
+var errLogFileNotOpened = errors.New("golog: log file is not opened")
+
 const (
-	logDirPerm     = 0755
-	logFileDateFmt = "20060102"
+	logDirPerm       = 0755
+	logFileDateFmt   = "20060102"
+	logFileHourFmt   = "2006010215"
+	logFileMinuteFmt = "200601021504"
+	logFileSecondFmt = "20060102150405"
+
+	RotateHourly = time.Hour
+	RotateDaily  = 24 * time.Hour
+	RotateWeekly = 7 * RotateDaily
 )
 
 type FileLogOptions struct {
@@ -22,6 +34,17 @@ type FileLogOptions struct {
 	LogDir string
 	// log file buffer size
 	Bufsize int
+	// max bytes of a log file, logs exceed it are written to next numbered file, <=0 to disable
+	MaxSize int64
+	// period of log files, such as RotateHourly, RotateDaily, RotateWeekly or any duration
+	// no less than a second, default RotateDaily
+	RotatePeriod time.Duration
+	// compress rotated log files in background if not nil
+	Compressor Compressor
+	// max total bytes of log files, oldest files are removed first when exceeded, <=0 to disable
+	MaxTotalBytes int64
+	// max count of log files, oldest files are removed first when exceeded, <=0 to disable
+	MaxFiles int
 }
 
 func (f *FileLogOptions) merge(o FileLogOptions) {
@@ -34,6 +57,21 @@ func (f *FileLogOptions) merge(o FileLogOptions) {
 	if o.Bufsize > 0 {
 		f.Bufsize = o.Bufsize
 	}
+	if o.MaxSize != 0 {
+		f.MaxSize = o.MaxSize
+	}
+	if o.RotatePeriod >= time.Second {
+		f.RotatePeriod = o.RotatePeriod
+	}
+	if o.Compressor != nil {
+		f.Compressor = o.Compressor
+	}
+	if o.MaxTotalBytes != 0 {
+		f.MaxTotalBytes = o.MaxTotalBytes
+	}
+	if o.MaxFiles != 0 {
+		f.MaxFiles = o.MaxFiles
+	}
 }
 
 func newDefaultFileLogOptions(options ...FileLogOptions) FileLogOptions {
@@ -41,6 +79,8 @@ func newDefaultFileLogOptions(options ...FileLogOptions) FileLogOptions {
 		ExpireDays: 14,
 		LogDir:     "logs",
 		Bufsize:    40960,
+
+		RotatePeriod: RotateDaily,
 	}
 	for _, o := range options {
 		opts.merge(o)
@@ -49,29 +89,69 @@ func newDefaultFileLogOptions(options ...FileLogOptions) FileLogOptions {
 }
 
 type (
+	// logNameParser parse date and sequence from name of log file created by writer.
+	logNameParser func(filename string) (date string, seq int, ok bool)
+
 	buffedFile struct {
+		name string
 		file *os.File
 		*bufio.Writer
+
+		// date of the rotation period and sequence of the file
+		date string
+		seq  int
+		size int64
 	}
 )
 
 func (bf *buffedFile) init(name string, bufsize int) error {
+	if bf.file != nil {
+		// the file may be reopened, flush first to get the real size
+		bf.Writer.Flush()
+	}
 	fd, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return err
 	}
+	stat, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return err
+	}
 	if bf.file != nil {
-		bf.Writer.Flush()
 		bf.file.Close()
 
 		bf.Writer.Reset(fd)
 	} else {
 		bf.Writer = bufio.NewWriterSize(fd, bufsize)
 	}
+	bf.name = name
 	bf.file = fd
+	bf.size = stat.Size()
 
 	return nil
 }
+
+func (bf *buffedFile) Write(b []byte) (int, error) {
+	if bf.Writer == nil {
+		return 0, errLogFileNotOpened
+	}
+	n, err := bf.Writer.Write(b)
+	bf.size += int64(n)
+	return n, err
+}
+
+// exceed reports whether writing n bytes makes a non-empty file larger than maxSize.
+func (bf *buffedFile) exceed(maxSize int64, n int) bool {
+	return maxSize > 0 && bf.size > 0 && bf.size+int64(n) > maxSize
+}
+func (bf *buffedFile) Flush() error {
+	if bf.Writer == nil {
+		return errLogFileNotOpened
+	}
+	return bf.Writer.Flush()
+}
+
 func (bf *buffedFile) Close() {
 	if bf.file != nil {
 		bf.Writer.Flush()
@@ -79,13 +159,83 @@ func (bf *buffedFile) Close() {
 	}
 }
 
+// logRotation tracks the period of current log files.
+type logRotation struct {
+	period time.Duration
+	end    time.Time
+}
+
+// check returns the formatted start time of the period containing now if now is
+// out of the current period.
+func (r *logRotation) check(now time.Time) (string, bool) {
+	if now.Before(r.end) {
+		return "", false
+	}
+	start, end := rotatePeriod(now, r.period)
+	r.end = end
+	return start.Format(rotateLayout(r.period)), true
+}
+
+// reset makes next check always succeed, it's used to retry after switching log file failed.
+func (r *logRotation) reset() {
+	r.end = time.Time{}
+}
+
+// rotateEpoch is the unix time of Monday 1970-01-05, periods are aligned to it
+// in local time so that daily periods start at midnight and weekly periods start on Monday.
+const rotateEpoch = 4 * 24 * 3600
+
+// rotatePeriod returns the start and end of the period containing t. Periods of whole
+// days are computed by calendar days as days may be 23 or 25 hours long across DST changes.
+func rotatePeriod(t time.Time, period time.Duration) (start, end time.Time) {
+	if period%RotateDaily == 0 {
+		y, m, d := t.Date()
+		days := int64(period / RotateDaily)
+		n := (time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() - rotateEpoch) / (24 * 3600)
+		d -= int((n%days + days) % days)
+		start = time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, int(days))
+	}
+
+	_, offset := t.Zone()
+	secs := t.Unix() + int64(offset) - rotateEpoch
+	p := int64(period / time.Second)
+	secs -= (secs%p + p) % p
+	start = time.Unix(secs+rotateEpoch-int64(offset), 0)
+	return start, start.Add(period)
+}
+
+func rotateLayout(period time.Duration) string {
+	switch {
+	case period%RotateDaily == 0:
+		return logFileDateFmt
+	case period%time.Hour == 0:
+		return logFileHourFmt
+	case period%time.Minute == 0:
+		return logFileMinuteFmt
+	default:
+		return logFileSecondFmt
+	}
+}
+
+// parseLogTime parse the date section of log file name in any of the rotation layouts.
+func parseLogTime(date string) (time.Time, bool) {
+	for _, layout := range []string{logFileDateFmt, logFileHourFmt, logFileMinuteFmt, logFileSecondFmt} {
+		if len(layout) == len(date) {
+			t, err := time.ParseInLocation(layout, date, time.Local)
+			return t, err == nil
+		}
+	}
+	return time.Time{}, false
+}
+
 type singleFileWriter struct {
 	opts FileLogOptions
 
-	mu       sync.Mutex
-	day      int
-	filedate string
-	file     buffedFile
+	mu          sync.Mutex
+	rotation    logRotation
+	file        buffedFile
+	compressing sync.WaitGroup
 }
 
 func SingleFile(options ...FileLogOptions) (Writer, error) {
@@ -96,35 +246,80 @@ func SingleFile(options ...FileLogOptions) (Writer, error) {
 	}
 
 	w := &singleFileWriter{
-		opts: opts,
-		day:  -1,
+		opts:     opts,
+		rotation: logRotation{period: opts.RotatePeriod},
+	}
+	err = w.checkRotate()
+	if err != nil {
+		return nil, err
 	}
-	w.checkDaily()
 
 	return w, nil
 }
 
-func (w *singleFileWriter) Write(level Level, bytes []byte) (err error) {
+// Write writes bytes to current log file, if it's failed to switch to a new file,
+// bytes are still written to the previous one and the error is returned.
+func (w *singleFileWriter) Write(level Level, bytes []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.checkDaily()
-	_, err = w.file.Write(bytes)
-	return
+	err := w.checkRotate()
+	if err == nil {
+		err = w.checkSize(len(bytes))
+	}
+	_, e := w.file.Write(bytes)
+	if err == nil {
+		err = e
+	}
+	return err
 }
 
-func (w *singleFileWriter) checkDaily() {
+func (w *singleFileWriter) checkRotate() error {
 	now := time.Now()
-	if d := uniqueLogDay(now); d != w.day {
-		w.day = d
-		date := now.Format(logFileDateFmt)
-		err := w.file.init(w.logfileName(date), w.opts.Bufsize)
-		if err == nil {
-			w.filedate = date
-		}
+	date, ok := w.rotation.check(now)
+	if !ok {
+		return nil
+	}
+
+	seq := lastLogSeq(w.opts.LogDir, func(filename string) (int, bool) {
+		d, seq, ok := w.parseLogDate(filename)
+		return seq, ok && d == date
+	})
+	err := w.file.init(w.logfileName(date, seq), w.opts.Bufsize)
+	if err != nil {
+		w.rotation.reset()
+		return err
+	}
+	w.file.date = date
+	w.file.seq = seq
+
+	w.cleanLogFiles(now)
+	w.compressLogFiles()
+	return nil
+}
+
+func (w *singleFileWriter) checkSize(n int) error {
+	if !w.file.exceed(w.opts.MaxSize, n) {
+		return nil
+	}
 
-		cleanLogFiles(&w.opts, w.filedate, w.parseLogDate)
+	seq := w.file.seq + 1
+	err := w.file.init(w.logfileName(w.file.date, seq), w.opts.Bufsize)
+	if err != nil {
+		return err
 	}
+	w.file.seq = seq
+	w.cleanLogFiles(time.Now())
+	w.compressLogFiles()
+	return nil
+}
+
+func (w *singleFileWriter) cleanLogFiles(now time.Time) {
+	cleanLogFiles(&w.opts, now, []string{w.file.name}, w.parseLogDate)
+}
+
+func (w *singleFileWriter) compressLogFiles() {
+	compressLogFiles(&w.opts, &w.compressing, []string{w.file.name}, w.parseLogDate)
 }
 
 func (w *singleFileWriter) Flush() {
@@ -139,51 +334,174 @@ func (w *singleFileWriter) Close() {
 	defer w.mu.Unlock()
 
 	w.file.Close()
+	w.compressing.Wait()
 }
 
-func (w *singleFileWriter) logfileName(datetime string) string {
-	return filepath.Join(w.opts.LogDir, datetime+".log")
+func (w *singleFileWriter) logfileName(datetime string, seq int) string {
+	return filepath.Join(w.opts.LogDir, logSeqName(datetime, seq))
 }
 
-func (w *singleFileWriter) parseLogDate(filename string) (string, bool) {
-	filename = filepath.Base(filename)
-	secs := strings.Split(filename, ".")
-	if len(secs) != 2 || secs[1] != "log" || secs[0] == "" {
-		return "", false
+func (w *singleFileWriter) parseLogDate(filename string) (date string, seq int, ok bool) {
+	secs, seq, ok := parseLogSeqName(filename, 1)
+	if !ok {
+		return "", 0, false
 	}
-	return secs[0], true
+	return secs[0], seq, true
 }
 
-func uniqueLogDay(t time.Time) int {
-	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+// logSeqName returns name of the seq-th log file of base, seq 0 is "base.log",
+// others are "base.seq.log".
+func logSeqName(base string, seq int) string {
+	if seq == 0 {
+		return base + ".log"
+	}
+	return base + "." + strconv.Itoa(seq) + ".log"
 }
 
-func cleanLogFiles(opts *FileLogOptions, nowdate string, parseLogDate func(string) (string, bool)) {
-	if opts.ExpireDays <= 0 {
-		return
+// parseLogSeqName parse filename created by logSeqName, the base must contains n
+// non-empty dot-separated sections. An extension after ".log" of compressed files is ignored.
+func parseLogSeqName(filename string, n int) (secs []string, seq int, ok bool) {
+	filename = filepath.Base(filename)
+	secs = strings.Split(filename, ".")
+	if l := len(secs); l > n+1 && secs[l-2] == "log" && secs[l-1] != "log" {
+		secs = secs[:l-1]
+	}
+	if len(secs) == n+2 {
+		var err error
+		seq, err = strconv.Atoi(secs[n])
+		if err != nil || seq <= 0 {
+			return nil, 0, false
+		}
+		secs = append(secs[:n], secs[n+1])
+	}
+	if len(secs) != n+1 || secs[n] != "log" {
+		return nil, 0, false
 	}
+	for _, sec := range secs[:n] {
+		if sec == "" {
+			return nil, 0, false
+		}
+	}
+	return secs[:n], seq, true
+}
 
-	items, err := ioutil.ReadDir(opts.LogDir)
+// lastLogSeq returns the max sequence of files in dir accepted by match, or the
+// next one if the file of max sequence was compressed.
+func lastLogSeq(dir string, match func(filename string) (int, bool)) int {
+	var (
+		last       int
+		compressed bool
+	)
+	items, err := ioutil.ReadDir(dir)
 	if err != nil {
+		return last
+	}
+	for _, item := range items {
+		if item.IsDir() {
+			continue
+		}
+		seq, ok := match(item.Name())
+		if !ok || seq < last {
+			continue
+		}
+		if seq > last {
+			last = seq
+			compressed = false
+		}
+		if !strings.HasSuffix(item.Name(), ".log") {
+			compressed = true
+		}
+	}
+	if compressed {
+		last++
+	}
+	return last
+}
+
+type logFile struct {
+	name    string
+	time    time.Time
+	seq     int
+	size    int64
+	current bool
+}
+
+// cleanLogFiles removes expired log files, then removes oldest log files until
+// the total size and count are within limits, the current ones are never removed.
+func cleanLogFiles(opts *FileLogOptions, now time.Time, current []string, parseLogDate logNameParser) {
+	if opts.ExpireDays <= 0 && opts.MaxTotalBytes <= 0 && opts.MaxFiles <= 0 {
 		return
 	}
-	nowT, err := time.Parse(logFileDateFmt, nowdate)
+
+	items, err := ioutil.ReadDir(opts.LogDir)
 	if err != nil {
 		return
 	}
-	nowT = nowT.In(time.Local)
 
-	expiredate := nowT.Add(-time.Hour * 24 * time.Duration(opts.ExpireDays)).Format(logFileDateFmt)
+	var (
+		start, _   = rotatePeriod(now, opts.RotatePeriod)
+		expireTime = start.AddDate(0, 0, -opts.ExpireDays)
+		files      []logFile
+		total      int64
+	)
 	for _, item := range items {
 		if item.IsDir() {
 			continue
 		}
-		date, ok := parseLogDate(item.Name())
+		date, seq, ok := parseLogDate(item.Name())
+		if !ok {
+			continue
+		}
+		t, ok := parseLogTime(date)
 		if !ok {
 			continue
 		}
-		if date < expiredate {
-			os.Remove(filepath.Join(opts.LogDir, item.Name()))
+		name := filepath.Join(opts.LogDir, item.Name())
+		file := logFile{
+			name:    name,
+			time:    t,
+			seq:     seq,
+			size:    item.Size(),
+			current: isCurrentLogFile(current, name),
+		}
+		if opts.ExpireDays > 0 && !file.current && t.Before(expireTime) {
+			os.Remove(file.name)
+			continue
+		}
+		files = append(files, file)
+		total += file.size
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		fi, fj := &files[i], &files[j]
+		if !fi.time.Equal(fj.time) {
+			return fi.time.Before(fj.time)
+		}
+		if fi.seq != fj.seq {
+			return fi.seq < fj.seq
+		}
+		return fi.name < fj.name
+	})
+	count := len(files)
+	for i := range files {
+		if (opts.MaxTotalBytes <= 0 || total <= opts.MaxTotalBytes) && (opts.MaxFiles <= 0 || count <= opts.MaxFiles) {
+			break
+		}
+		if files[i].current {
+			continue
+		}
+		if os.Remove(files[i].name) == nil {
+			total -= files[i].size
+			count--
+		}
+	}
+}
+
+func isCurrentLogFile(current []string, name string) bool {
+	for _, c := range current {
+		if c == name {
+			return true
 		}
 	}
+	return false
 }
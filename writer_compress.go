@@ -0,0 +1,119 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Compressor compress rotated log files.
+type Compressor interface {
+	// Ext returns the extension appended to name of compressed files, such as ".gz".
+	Ext() string
+	Compress(dst io.Writer, src io.Reader) error
+}
+
+type gzipCompressor struct {
+	level int
+}
+
+// GzipCompressor create a Compressor produces ".gz" files, level is one of the
+// compress/gzip levels, 0 means gzip.DefaultCompression.
+func GzipCompressor(level int) Compressor {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzipCompressor{level: level}
+}
+
+func (gzipCompressor) Ext() string {
+	return ".gz"
+}
+
+func (c gzipCompressor) Compress(dst io.Writer, src io.Reader) error {
+	w, err := gzip.NewWriterLevel(dst, c.level)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, src)
+	if e := w.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// compressingFiles records files being compressed to prevent compressing one file twice.
+var compressingFiles sync.Map
+
+// compressLogFiles compress uncompressed log files accepted by parseLogDate in background,
+// except the current ones which are being written.
+func compressLogFiles(opts *FileLogOptions, wg *sync.WaitGroup, current []string, parseLogDate logNameParser) {
+	if opts.Compressor == nil {
+		return
+	}
+	items, err := ioutil.ReadDir(opts.LogDir)
+	if err != nil {
+		return
+	}
+
+	var names []string
+	for _, item := range items {
+		if item.IsDir() || !strings.HasSuffix(item.Name(), ".log") {
+			continue
+		}
+		if _, _, ok := parseLogDate(item.Name()); !ok {
+			continue
+		}
+		name := filepath.Join(opts.LogDir, item.Name())
+		if isCurrentLogFile(current, name) {
+			continue
+		}
+		if _, loaded := compressingFiles.LoadOrStore(name, struct{}{}); !loaded {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	wg.Add(1)
+	go func(c Compressor, names []string) {
+		defer wg.Done()
+
+		for _, name := range names {
+			compressLogFile(c, name)
+			compressingFiles.Delete(name)
+		}
+	}(opts.Compressor, names)
+}
+
+func compressLogFile(c Compressor, name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmpname := name + c.Ext() + ".tmp"
+	dst, err := os.OpenFile(tmpname, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	err = c.Compress(dst, src)
+	if e := dst.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmpname, name+c.Ext())
+	}
+	if err != nil {
+		os.Remove(tmpname)
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
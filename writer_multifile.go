@@ -3,7 +3,6 @@ package golog
 import (
 	"os"
 	"path/filepath"
-	"strings"
 	"sync"
 	"time"
 )
@@ -12,10 +11,10 @@ type multiFileWriter struct {
 	level Level
 	opts  FileLogOptions
 
-	files    []buffedFile
-	day      int
-	filedate string
-	mu       sync.Mutex
+	files       []buffedFile
+	rotation    logRotation
+	mu          sync.Mutex
+	compressing sync.WaitGroup
 }
 
 func MultiFile(logLevel Level, options ...FileLogOptions) (Writer, error) {
@@ -26,45 +25,107 @@ func MultiFile(logLevel Level, options ...FileLogOptions) (Writer, error) {
 	}
 
 	w := &multiFileWriter{
-		level: logLevel,
-		opts:  opts,
-		day:   -1,
-		files: make([]buffedFile, levelMax+1),
+		level:    logLevel,
+		opts:     opts,
+		rotation: logRotation{period: opts.RotatePeriod},
+		files:    make([]buffedFile, levelCount),
+	}
+	err = w.checkRotate()
+	if err != nil {
+		return nil, err
 	}
-	w.checkDaily()
 
 	return w, nil
 }
 
-func (w *multiFileWriter) Write(level Level, bytes []byte) (err error) {
+// Write writes bytes to log files of levels from the writer level to level, the first
+// error is returned, same as singleFileWriter, bytes are still written to previous files
+// if it's failed to switch to new files.
+func (w *multiFileWriter) Write(level Level, bytes []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.checkDaily()
-	for l := w.level; l <= level && err == nil; l++ {
-		_, err = w.files[l].Write(bytes)
+	err := w.checkRotate()
+	for l := w.level; l <= level; l++ {
+		e := w.checkSize(l, len(bytes))
+		if _, we := w.files[l-levelMin].Write(bytes); e == nil {
+			e = we
+		}
+		if err == nil {
+			err = e
+		}
 	}
-	return
+	return err
 }
 
-func (w *multiFileWriter) checkDaily() {
+func (w *multiFileWriter) checkRotate() error {
 	now := time.Now()
-	if d := now.Day(); d != w.day {
-		w.day = d
-		datetime := now.Format(logFileDateFmt)
-
-		var err error
-		for l := w.level; l <= levelMax; l++ {
-			e := w.files[l].init(w.logfileName(l, datetime), w.opts.Bufsize)
-			if e != nil {
-				err = e
-			}
-		}
-		if err == nil {
-			w.filedate = datetime
+	datetime, ok := w.rotation.check(now)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	for l := w.level; l <= levelMax; l++ {
+		seq := w.lastLogSeq(l, datetime)
+		e := w.files[l-levelMin].init(w.logfileName(l, datetime, seq), w.opts.Bufsize)
+		if e != nil {
+			err = e
+		} else {
+			w.files[l-levelMin].date = datetime
+			w.files[l-levelMin].seq = seq
 		}
-		cleanLogFiles(&w.opts, w.filedate, w.parseLogDate)
 	}
+	if err != nil {
+		w.rotation.reset()
+		return err
+	}
+	w.cleanLogFiles(now)
+	w.compressLogFiles()
+	return nil
+}
+
+func (w *multiFileWriter) checkSize(level Level, n int) error {
+	file := &w.files[level-levelMin]
+	if !file.exceed(w.opts.MaxSize, n) {
+		return nil
+	}
+
+	seq := file.seq + 1
+	err := file.init(w.logfileName(level, file.date, seq), w.opts.Bufsize)
+	if err != nil {
+		return err
+	}
+	file.seq = seq
+	w.cleanLogFiles(time.Now())
+	w.compressLogFiles()
+	return nil
+}
+
+func (w *multiFileWriter) currentFiles() []string {
+	current := make([]string, 0, levelCount)
+	for l := w.level; l <= levelMax; l++ {
+		current = append(current, w.files[l-levelMin].name)
+	}
+	return current
+}
+
+func (w *multiFileWriter) cleanLogFiles(now time.Time) {
+	cleanLogFiles(&w.opts, now, w.currentFiles(), w.parseLogDate)
+}
+
+func (w *multiFileWriter) compressLogFiles() {
+	if w.opts.Compressor == nil {
+		return
+	}
+	compressLogFiles(&w.opts, &w.compressing, w.currentFiles(), w.parseLogDate)
+}
+
+func (w *multiFileWriter) lastLogSeq(level Level, datetime string) int {
+	return lastLogSeq(w.opts.LogDir, func(filename string) (int, bool) {
+		l, date, seq, ok := w.parseLogFile(filename)
+		return seq, ok && l == level.String() && date == datetime
+	})
 }
 
 func (w *multiFileWriter) Flush() {
@@ -72,7 +133,7 @@ func (w *multiFileWriter) Flush() {
 	defer w.mu.Unlock()
 
 	for l := w.level; l <= levelMax; l++ {
-		w.files[l].Flush()
+		w.files[l-levelMin].Flush()
 	}
 }
 
@@ -81,19 +142,29 @@ func (w *multiFileWriter) Close() {
 	defer w.mu.Unlock()
 
 	for l := w.level; l <= levelMax; l++ {
-		w.files[l].Close()
+		w.files[l-levelMin].Close()
 	}
+	w.compressing.Wait()
 }
 
-func (w *multiFileWriter) logfileName(level Level, datetime string) string {
-	return filepath.Join(w.opts.LogDir, level.String()+"."+datetime+".log")
+func (w *multiFileWriter) logfileName(level Level, datetime string, seq int) string {
+	return filepath.Join(w.opts.LogDir, logSeqName(level.String()+"."+datetime, seq))
 }
 
-func (w *multiFileWriter) parseLogDate(filename string) (string, bool) {
-	filename = filepath.Base(filename)
-	secs := strings.Split(filename, ".")
-	if len(secs) != 3 || secs[2] != "log" || secs[0] == "" || secs[1] == "" {
-		return "", false
+func (w *multiFileWriter) parseLogFile(filename string) (level, date string, seq int, ok bool) {
+	secs, seq, ok := parseLogSeqName(filename, 2)
+	if !ok {
+		return "", "", 0, false
 	}
-	return secs[1], true
+	for l := levelMin; l <= levelMax; l++ {
+		if secs[0] == l.String() {
+			return secs[0], secs[1], seq, true
+		}
+	}
+	return "", "", 0, false
+}
+
+func (w *multiFileWriter) parseLogDate(filename string) (date string, seq int, ok bool) {
+	_, date, seq, ok = w.parseLogFile(filename)
+	return date, seq, ok
 }
@@ -0,0 +1,317 @@
+package golog
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listLogFiles(t *testing.T, dir string) []string {
+	items, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSingleFileMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := SingleFile(FileLogOptions{LogDir: dir, MaxSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := []byte(strings.Repeat("a", 39) + "\n")
+	for i := 0; i < 5; i++ {
+		w.Write(LevelInfo, line)
+	}
+	w.Close()
+
+	date := time.Now().Format(logFileDateFmt)
+	expect := []string{date + ".1.log", date + ".2.log", date + ".log"}
+	if names := listLogFiles(t, dir); strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect files %v, got %v", expect, names)
+	}
+
+	w, err = SingleFile(FileLogOptions{LogDir: dir, MaxSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(LevelInfo, line)
+	w.Close()
+	data, _ := ioutil.ReadFile(filepath.Join(dir, date+".2.log"))
+	if len(data) != 2*len(line) {
+		t.Fatalf("expect reopened writer appending to last file, got %d bytes", len(data))
+	}
+}
+
+func TestMultiFileMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := MultiFile(LevelWarn, FileLogOptions{LogDir: dir, MaxSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := []byte(strings.Repeat("a", 39) + "\n")
+	for i := 0; i < 3; i++ {
+		w.Write(LevelWarn, line)
+	}
+	w.Write(LevelError, line)
+	w.Close()
+
+	date := time.Now().Format(logFileDateFmt)
+	for name, lines := range map[string]int{
+		"WARN." + date + ".log":   2,
+		"WARN." + date + ".1.log": 2,
+		"ERROR." + date + ".log":  1,
+	} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != lines*len(line) {
+			t.Errorf("expect %d lines in %s, got %d bytes", lines, name, len(data))
+		}
+	}
+}
+
+func TestParseLogDate(t *testing.T) {
+	single := &singleFileWriter{}
+	multi := &multiFileWriter{}
+	for _, c := range []struct {
+		Parse    logNameParser
+		Filename string
+		Date     string
+		OK       bool
+	}{
+		{single.parseLogDate, "20261017.log", "20261017", true},
+		{single.parseLogDate, "20261017.2.log", "20261017", true},
+		{single.parseLogDate, "20261017.0.log", "", false},
+		{single.parseLogDate, "20261017.x.log", "", false},
+		{single.parseLogDate, ".log", "", false},
+		{multi.parseLogDate, "INFO.20261017.log", "20261017", true},
+		{multi.parseLogDate, "INFO.20261017.12.log", "20261017", true},
+		{multi.parseLogDate, "20261017.12.log", "", false},
+		{multi.parseLogDate, "INFO..log", "", false},
+		{multi.parseLogDate, "NONE.20261017.log", "", false},
+	} {
+		date, _, ok := c.Parse(c.Filename)
+		if date != c.Date || ok != c.OK {
+			t.Errorf("%s: expect %s %t, got %s %t", c.Filename, c.Date, c.OK, date, ok)
+		}
+	}
+}
+
+func TestRotatePeriod(t *testing.T) {
+	now := time.Date(2026, 10, 17, 15, 42, 13, 0, time.Local)
+	for _, c := range []struct {
+		Period time.Duration
+		Name   string
+	}{
+		{RotateHourly, "2026101715"},
+		{RotateDaily, "20261017"},
+		{RotateWeekly, "20261012"},
+		{2 * RotateDaily, "20261016"},
+		{15 * time.Minute, "202610171530"},
+		{10 * time.Second, "20261017154210"},
+	} {
+		r := logRotation{period: c.Period}
+		name, ok := r.check(now)
+		if !ok || name != c.Name {
+			t.Errorf("period %s: expect %s, got %s", c.Period, c.Name, name)
+		}
+		if _, ok = r.check(now.Add(time.Second)); ok {
+			t.Errorf("period %s: unexpected rotation in same period", c.Period)
+		}
+		if _, ok = r.check(now.Add(c.Period)); !ok {
+			t.Errorf("period %s: expect rotation in next period", c.Period)
+		}
+	}
+}
+
+func TestRotatePeriodDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	local := time.Local
+	time.Local = loc
+	defer func() { time.Local = local }()
+
+	// 2026-03-08 is 23 hours long and 2026-11-01 is 25 hours long in New York.
+	for _, c := range []struct {
+		Period     time.Duration
+		Now        time.Time
+		Start, End string
+	}{
+		{RotateDaily, time.Date(2026, 3, 8, 0, 30, 0, 0, loc), "20260308", "20260309"},
+		{RotateDaily, time.Date(2026, 3, 8, 23, 30, 0, 0, loc), "20260308", "20260309"},
+		{RotateDaily, time.Date(2026, 11, 1, 23, 30, 0, 0, loc), "20261101", "20261102"},
+		{RotateWeekly, time.Date(2026, 3, 8, 23, 30, 0, 0, loc), "20260302", "20260309"},
+		{RotateWeekly, time.Date(2026, 3, 9, 0, 30, 0, 0, loc), "20260309", "20260316"},
+		{2 * RotateDaily, time.Date(2026, 3, 9, 23, 30, 0, 0, loc), "20260308", "20260310"},
+	} {
+		r := logRotation{period: c.Period}
+		name, ok := r.check(c.Now)
+		if !ok || name != c.Start {
+			t.Errorf("%s period %s: expect start %s, got %s", c.Now, c.Period, c.Start, name)
+		}
+		if end := r.end.Format(logFileDateFmt); end != c.End || r.end.Hour() != 0 || r.end.Minute() != 0 {
+			t.Errorf("%s period %s: expect end at midnight of %s, got %s", c.Now, c.Period, c.End, r.end)
+		}
+	}
+}
+
+func TestCleanHourlyLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	var names []string
+	for _, d := range []time.Duration{0, 47 * time.Hour, 49 * time.Hour, 72 * time.Hour} {
+		name := now.Add(-d).Format(logFileHourFmt) + ".log"
+		names = append(names, name)
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ioutil.WriteFile(filepath.Join(dir, "other.log"), nil, 0644)
+
+	w, err := SingleFile(FileLogOptions{LogDir: dir, ExpireDays: 2, RotatePeriod: RotateHourly})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	expect := []string{names[1], names[0], "other.log"}
+	if got := listLogFiles(t, dir); strings.Join(got, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect files %v, got %v", expect, got)
+	}
+}
+
+func TestCompressLogFiles(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Now().Format(logFileDateFmt)
+	old := time.Now().Add(-RotateDaily).Format(logFileDateFmt) + ".log"
+	ioutil.WriteFile(filepath.Join(dir, old), []byte("old\n"), 0644)
+	ioutil.WriteFile(filepath.Join(dir, date+".log.gz"), nil, 0644)
+
+	w, err := SingleFile(FileLogOptions{LogDir: dir, MaxSize: 10, Compressor: GzipCompressor(0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := []byte("aaaaaaaa\n")
+	for i := 0; i < 3; i++ {
+		w.Write(LevelInfo, line)
+	}
+	w.Close()
+
+	expect := []string{old + ".gz", date + ".1.log.gz", date + ".2.log.gz", date + ".3.log", date + ".log.gz"}
+	if names := listLogFiles(t, dir); strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect files %v, got %v", expect, names)
+	}
+
+	f, err := os.Open(filepath.Join(dir, old+".gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadAll(r); string(data) != "old\n" {
+		t.Fatalf("unexpected content of compressed file: %q", data)
+	}
+
+	single := &singleFileWriter{}
+	if date, seq, ok := single.parseLogDate("20261016.2.log.gz"); !ok || date != "20261016" || seq != 2 {
+		t.Fatal("compressed log files should be recognised")
+	}
+}
+
+func TestRetentionLimits(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Now().Format(logFileDateFmt)
+	old := time.Now().Add(-RotateDaily).Format(logFileDateFmt)
+	for _, name := range []string{old + ".log", old + ".2.log", old + ".10.log"} {
+		ioutil.WriteFile(filepath.Join(dir, name), []byte("aaaaaaaaa\n"), 0644)
+	}
+
+	w, err := SingleFile(FileLogOptions{LogDir: dir, MaxFiles: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{old + ".10.log", date + ".log"}
+	if names := listLogFiles(t, dir); strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect files %v, got %v", expect, names)
+	}
+	w.Close()
+
+	dir = t.TempDir()
+	w, err = MultiFile(LevelError, FileLogOptions{LogDir: dir, MaxSize: 10, MaxTotalBytes: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := []byte("aaaaaaaaa\n")
+	for i := 0; i < 3; i++ {
+		w.Write(LevelError, line)
+		w.Flush()
+	}
+	w.Close()
+	expect = []string{"ERROR." + date + ".1.log", "ERROR." + date + ".2.log", "FATAL." + date + ".log", "PANIC." + date + ".log"}
+	if names := listLogFiles(t, dir); strings.Join(names, ",") != strings.Join(expect, ",") {
+		t.Fatalf("expect files %v, got %v", expect, names)
+	}
+}
+
+func TestFileOpenError(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Now().Format(logFileDateFmt)
+	if err := os.Mkdir(filepath.Join(dir, date+".log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SingleFile(FileLogOptions{LogDir: dir}); err == nil {
+		t.Fatal("expect error when log file can't be opened")
+	}
+
+	w := &singleFileWriter{opts: newDefaultFileLogOptions(FileLogOptions{LogDir: dir})}
+	w.rotation.period = w.opts.RotatePeriod
+	if err := w.Write(LevelInfo, []byte("a\n")); err == nil {
+		t.Fatal("expect error when writing without opened file")
+	}
+	w.Flush()
+	w.Close()
+}
+
+func TestMultiFilePartialRotate(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Now().Format(logFileDateFmt)
+	if err := os.Mkdir(filepath.Join(dir, "ERROR."+date+".log"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w := &multiFileWriter{
+		level: LevelWarn,
+		opts:  newDefaultFileLogOptions(FileLogOptions{LogDir: dir, MaxSize: 100}),
+		files: make([]buffedFile, levelCount),
+	}
+	w.rotation.period = w.opts.RotatePeriod
+	line := []byte(strings.Repeat("a", 39) + "\n")
+	for i := 0; i < 3; i++ {
+		if err := w.Write(LevelWarn, line); err == nil {
+			t.Fatal("expect error when ERROR log file can't be opened")
+		}
+	}
+	w.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, "WARN."+date+".1.log")); err != nil {
+		t.Fatalf("expect rolled file under current date: %v", listLogFiles(t, dir))
+	}
+}